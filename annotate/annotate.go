@@ -0,0 +1,207 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package annotate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/sentiment"
+	"github.com/jlubawy/go-gcnl/syntax"
+)
+
+// Method is the name of the API method used by requests.
+const Method = "annotateText"
+
+// Endpoint is the URL of the method when using the default Client settings.
+const Endpoint = "https://language.googleapis.com/v1beta1/documents:annotateText"
+
+var (
+	ErrMissingKey = gcnl.ErrMissingKey
+	ErrNoClient   = gcnl.ErrNoClient
+	ErrNoFeatures = errors.New("must enable at least one feature")
+)
+
+// Features selects which analyses are performed by a single annotateText call.
+type Features struct {
+	ExtractSyntax            bool `json:"extractSyntax"`
+	ExtractEntities          bool `json:"extractEntities"`
+	ExtractDocumentSentiment bool `json:"extractDocumentSentiment"`
+}
+
+// AllFeatures enables every analysis.
+var AllFeatures = Features{
+	ExtractSyntax:            true,
+	ExtractEntities:          true,
+	ExtractDocumentSentiment: true,
+}
+
+// A Sentence is a sentence found in the document. Sentiment is only set when
+// ExtractDocumentSentiment is enabled.
+type Sentence struct {
+	syntax.Sentence
+	Sentiment *sentiment.Sentiment `json:"sentiment,omitempty"`
+}
+
+// A Result contains the output of each analysis enabled by the request
+// Features. Fields of analyses that were not enabled are left empty.
+type Result struct {
+	Sentences         []Sentence           `json:"sentences"`
+	Tokens            syntax.Tokens        `json:"tokens"`
+	Entities          []entities.Entity    `json:"entities"`
+	DocumentSentiment *sentiment.Sentiment `json:"documentSentiment"`
+	Language          string               `json:"language"`
+}
+
+// EntityMap returns the entities of the result grouped by type.
+func (res *Result) EntityMap() entities.Map {
+	return entities.NewMap(res.Entities)
+}
+
+// A Request sends documents to the annotateText API. It must be created using
+// NewRequest or NewClientRequest; the zero value fails with ErrNoClient. A
+// Request is safe for concurrent use and may be reused for any number of
+// documents.
+type Request struct {
+	client   *gcnl.Client
+	features Features
+	enc      gcnl.Encoding
+}
+
+// requestBody is the JSON object sent to the annotateText API.
+type requestBody struct {
+	Doc      gcnl.Document `json:"document"`
+	Features Features      `json:"features"`
+	Enc      gcnl.Encoding `json:"encodingType"`
+}
+
+// Document satisfies the gcnl.DocumentRequest interface for requestBody.
+func (body *requestBody) Document() gcnl.Document {
+	return body.Doc
+}
+
+// NewRequest returns a Request object with the given API key that performs the
+// analyses selected by features.
+func NewRequest(key string, features Features) *Request {
+	return NewClientRequest(gcnl.NewClient(key), features)
+}
+
+// NewClientRequest returns a Request object sent using the given Client that
+// performs the analyses selected by features.
+func NewClientRequest(c *gcnl.Client, features Features) *Request {
+	return &Request{
+		client:   c,
+		features: features,
+		enc:      gcnl.EncodingDefault,
+	}
+}
+
+// WithEncoding returns a copy of the request that has the API compute offsets
+// using enc. Use gcnl.ByteOffset to convert the returned offsets into indexes
+// of the document content.
+func (req *Request) WithEncoding(enc gcnl.Encoding) *Request {
+	return &Request{
+		client:   req.client,
+		features: req.features,
+		enc:      enc,
+	}
+}
+
+// Encoding returns the encoding used by the API to compute offsets.
+func (req *Request) Encoding() gcnl.Encoding {
+	return req.enc
+}
+
+// Features returns the analyses performed by the request.
+func (req *Request) Features() Features {
+	return req.features
+}
+
+// FromURL annotates the content retrieved from a given URL. It expects the
+// content retrieved from URL to be valid HTML.
+func (req *Request) FromURL(url string) (res *Result, err error) {
+	return req.FromURLContext(context.Background(), url)
+}
+
+// FromURLContext is like FromURL but uses ctx for both fetching the URL, using
+// the Fetcher of the client, and the API request.
+func (req *Request) FromURLContext(ctx context.Context, url string) (res *Result, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+	doc, err := req.client.FetchHTML(ctx, url)
+	if err != nil {
+		return
+	}
+	return req.FromDocumentContext(ctx, doc)
+}
+
+// FromPlainText annotates the given plain text.
+func (req *Request) FromPlainText(content string) (res *Result, err error) {
+	return req.FromPlainTextContext(context.Background(), content)
+}
+
+// FromPlainTextContext is like FromPlainText but uses ctx for the API request.
+func (req *Request) FromPlainTextContext(ctx context.Context, content string) (res *Result, err error) {
+	return req.FromDocumentContext(ctx, gcnl.NewPlainTextDocument(content))
+}
+
+// FromDocument annotates the given document.
+func (req *Request) FromDocument(doc gcnl.Document) (res *Result, err error) {
+	return req.FromDocumentContext(context.Background(), doc)
+}
+
+// FromDocumentContext is like FromDocument but uses ctx for the API request.
+func (req *Request) FromDocumentContext(ctx context.Context, doc gcnl.Document) (res *Result, err error) {
+	return req.do(ctx, doc)
+}
+
+// Do makes the actual API request for a given document.
+func (req *Request) do(ctx context.Context, doc gcnl.Document) (res *Result, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+	if req.features == (Features{}) {
+		err = ErrNoFeatures
+		return
+	}
+
+	res = new(Result)
+	body := &requestBody{Doc: doc, Features: req.features, Enc: req.enc}
+	if err = req.client.DoContext(ctx, Method, body, res); err != nil {
+		res = nil
+		return
+	}
+
+	for i := range res.Sentences {
+		res.Sentences[i].Text.Encoding = req.enc
+	}
+	for i := range res.Tokens {
+		res.Tokens[i].Text.Encoding = req.enc
+	}
+	for i := range res.Entities {
+		for j := range res.Entities[i].Mentions {
+			res.Entities[i].Mentions[j].TextSpan.Encoding = req.enc
+		}
+	}
+	return
+}
@@ -0,0 +1,94 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package annotate_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl/annotate"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+func TestZeroRequest(t *testing.T) {
+	var req annotate.Request
+	if _, err := req.FromPlainText("Larry Page founded Google."); err != annotate.ErrNoClient {
+		t.Errorf("got error %v, want %v", err, annotate.ErrNoClient)
+	}
+}
+
+func TestNoFeatures(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+
+	req := annotate.NewClientRequest(srv.Client(), annotate.Features{})
+	if _, err := req.FromPlainText("Larry Page founded Google."); err != annotate.ErrNoFeatures {
+		t.Errorf("got error %v, want %v", err, annotate.ErrNoFeatures)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("sent %d requests, want 0", n)
+	}
+}
+
+func TestRequestConcurrentReuse(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	req := annotate.NewClientRequest(srv.Client(), annotate.Features{ExtractEntities: true})
+	names := []string{"Larry Page", "Sergey Brin", "Eric Schmidt"}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			res, err := req.FromPlainText("I met " + name + " today.")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(res.Entities) != 1 || res.Entities[0].Name != name {
+				t.Errorf("got entities %+v, want only %q", res.Entities, name)
+			}
+			if len(res.Tokens) != 0 || res.DocumentSentiment != nil {
+				t.Error("got results of analyses that were not enabled")
+			}
+		}(name)
+	}
+	wg.Wait()
+}
+
+func TestSentenceSentiment(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	for _, f := range []annotate.Features{{ExtractSyntax: true}, annotate.AllFeatures} {
+		res, err := annotate.NewClientRequest(srv.Client(), f).FromPlainText("What a great day.")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Sentences) != 1 || res.Sentences[0].Text.Content != "What a great day." {
+			t.Fatalf("got sentences %+v", res.Sentences)
+		}
+		if got, want := res.Sentences[0].Sentiment != nil, f.ExtractDocumentSentiment; got != want {
+			t.Errorf("features %+v: sentence has sentiment %v, want %v", f, got, want)
+		}
+	}
+}
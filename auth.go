@@ -0,0 +1,325 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials authenticate the requests sent by a Client.
+type Credentials interface {
+	// Authorize adds authentication to r before it is sent.
+	Authorize(ctx context.Context, r *http.Request) error
+}
+
+// An APIKey authenticates requests using an API key. The key is sent in the
+// X-Goog-Api-Key header rather than in the URL so that it does not end up in
+// logs or error messages.
+type APIKey string
+
+// Authorize satisfies the Credentials interface for APIKey.
+func (k APIKey) Authorize(ctx context.Context, r *http.Request) error {
+	if len(k) == 0 {
+		return ErrMissingKey
+	}
+	r.Header.Set("X-Goog-Api-Key", string(k))
+	return nil
+}
+
+// String redacts the key when printed.
+func (k APIKey) String() string { return "APIKey(REDACTED)" }
+
+// GoString is like String, for the %#v verb.
+func (k APIKey) GoString() string { return k.String() }
+
+// A BearerToken authenticates requests using a fixed OAuth2 access token.
+type BearerToken string
+
+// Authorize satisfies the Credentials interface for BearerToken.
+func (t BearerToken) Authorize(ctx context.Context, r *http.Request) error {
+	if len(t) == 0 {
+		return ErrMissingKey
+	}
+	r.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// String redacts the token when printed.
+func (t BearerToken) String() string { return "BearerToken(REDACTED)" }
+
+// GoString is like String, for the %#v verb.
+func (t BearerToken) GoString() string { return t.String() }
+
+// ScopeCloudLanguage is the OAuth2 scope of the Natural Language API.
+const ScopeCloudLanguage = "https://www.googleapis.com/auth/cloud-language"
+
+// DefaultTokenURL is the Google OAuth2 token endpoint.
+const DefaultTokenURL = "https://oauth2.googleapis.com/token"
+
+// tokenExpiryDelta is how long before its expiry a cached token is refreshed.
+const tokenExpiryDelta = time.Minute
+
+// defaultTokenLifetime is the lifetime of tokens whose response has no
+// expires_in.
+const defaultTokenLifetime = time.Hour
+
+// A ServiceAccount authenticates requests using OAuth2 access tokens obtained
+// by signing JWT assertions with a service account private key. Tokens are
+// cached and refreshed shortly before they expire. A ServiceAccount is safe
+// for concurrent use.
+type ServiceAccount struct {
+	Email        string
+	PrivateKey   *rsa.PrivateKey
+	PrivateKeyID string
+
+	// TokenURL is the endpoint access tokens are requested from. If empty,
+	// DefaultTokenURL is used.
+	TokenURL string
+
+	// Scopes are the OAuth2 scopes requested. If empty, ScopeCloudLanguage
+	// is used.
+	Scopes []string
+
+	// HTTPClient is used to request tokens. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// String redacts the private key when the service account is printed.
+func (sa *ServiceAccount) String() string {
+	return fmt.Sprintf("gcnl.ServiceAccount{Email:%q, PrivateKeyID:%q, PrivateKey:REDACTED}", sa.Email, sa.PrivateKeyID)
+}
+
+// GoString is like String, for the %#v verb.
+func (sa *ServiceAccount) GoString() string {
+	return sa.String()
+}
+
+// NewServiceAccount parses the JSON key file of a service account as
+// downloaded from the Google Cloud console.
+func NewServiceAccount(data []byte) (sa *ServiceAccount, err error) {
+	var f struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err = json.Unmarshal(data, &f); err != nil {
+		return
+	}
+	if f.Type != "service_account" {
+		err = fmt.Errorf("gcnl: credentials type %q is not service_account", f.Type)
+		return
+	}
+
+	key, err := parsePrivateKey([]byte(f.PrivateKey))
+	if err != nil {
+		return
+	}
+
+	sa = &ServiceAccount{
+		Email:        f.ClientEmail,
+		PrivateKey:   key,
+		PrivateKeyID: f.PrivateKeyID,
+		TokenURL:     f.TokenURI,
+	}
+	return
+}
+
+// NewServiceAccountFile is like NewServiceAccount but reads the key file at
+// the given path.
+func NewServiceAccountFile(path string) (*ServiceAccount, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewServiceAccount(data)
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("gcnl: private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("gcnl: private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// Authorize satisfies the Credentials interface for ServiceAccount.
+func (sa *ServiceAccount) Authorize(ctx context.Context, r *http.Request) error {
+	token, err := sa.Token(ctx)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token returns a valid access token, requesting a new one if the cached token
+// is missing or about to expire.
+func (sa *ServiceAccount) Token(ctx context.Context) (string, error) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if len(sa.token) > 0 && time.Now().Add(tokenExpiryDelta).Before(sa.expiry) {
+		return sa.token, nil
+	}
+
+	token, expiry, err := sa.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	sa.token, sa.expiry = token, expiry
+	return token, nil
+}
+
+func (sa *ServiceAccount) tokenURL() string {
+	if len(sa.TokenURL) == 0 {
+		return DefaultTokenURL
+	}
+	return sa.TokenURL
+}
+
+// assertion returns a signed JWT asserting the service account identity.
+func (sa *ServiceAccount) assertion(now time.Time) (string, error) {
+	if sa.PrivateKey == nil {
+		return "", errors.New("gcnl: service account has no private key")
+	}
+
+	scopes := sa.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{ScopeCloudLanguage}
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": sa.PrivateKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   sa.Email,
+		"scope": strings.Join(scopes, " "),
+		"aud":   sa.tokenURL(),
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(nil, sa.PrivateKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// fetchToken exchanges a signed assertion for an access token.
+func (sa *ServiceAccount) fetchToken(ctx context.Context) (token string, expiry time.Time, err error) {
+	now := time.Now()
+	assertion, err := sa.assertion(now)
+	if err != nil {
+		return
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", sa.tokenURL(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := sa.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		err = fmt.Errorf("gcnl: token request returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		return
+	}
+
+	var jsonResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&jsonResp); err != nil {
+		return
+	}
+	if len(jsonResp.AccessToken) == 0 {
+		err = errors.New("gcnl: token response has no access_token")
+		return
+	}
+
+	// Google tokens last an hour, assume so if the response does not say
+	expiresIn := time.Duration(jsonResp.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = defaultTokenLifetime
+	}
+
+	token = jsonResp.AccessToken
+	expiry = now.Add(expiresIn)
+	return
+}
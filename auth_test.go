@@ -0,0 +1,234 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedaction(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa := &ServiceAccount{Email: "sa@example.iam.gserviceaccount.com", PrivateKey: key}
+
+	secrets := []string{"supersecret", key.D.String(), key.Primes[0].String()}
+	for _, v := range []interface{}{
+		NewClient("supersecret"),
+		&Client{Credentials: sa},
+		&Client{Credentials: BearerToken("supersecret")},
+		sa,
+		APIKey("supersecret"),
+		BearerToken("supersecret"),
+	} {
+		for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+			s := fmt.Sprintf(format, v)
+			for _, secret := range secrets {
+				if strings.Contains(s, secret) {
+					t.Errorf("%s of %T leaks a secret: %s", format, v, s)
+				}
+			}
+		}
+	}
+}
+
+// newTokenServer returns an OAuth2 token endpoint that verifies JWT assertions
+// using pub and answers with the access tokens "token-1", "token-2"... The
+// expires_in field is omitted if expiresIn is zero. n counts the token
+// requests.
+func newTokenServer(t *testing.T, pub *rsa.PublicKey, email, scope string, expiresIn int, n *int32) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifyAssertion(r, pub, email, scope, srv.URL+"/token"); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", atomic.AddInt32(n, 1)),
+			"token_type":   "Bearer",
+		}
+		if expiresIn != 0 {
+			resp["expires_in"] = expiresIn
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	return srv
+}
+
+// verifyAssertion checks the token request r the way the Google token
+// endpoint does.
+func verifyAssertion(r *http.Request, pub *rsa.PublicKey, email, scope, aud string) error {
+	if r.Method != "POST" || r.URL.Path != "/token" {
+		return fmt.Errorf("got %s %s, want POST /token", r.Method, r.URL.Path)
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	if gt := r.PostForm.Get("grant_type"); gt != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		return fmt.Errorf("got grant_type %q", gt)
+	}
+
+	parts := strings.Split(r.PostForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("assertion has %d parts, want 3", len(parts))
+	}
+	enc := base64.RawURLEncoding
+
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+		return fmt.Errorf("assertion signature: %v", err)
+	}
+
+	var header struct {
+		Alg, Typ, Kid string
+	}
+	var claims struct {
+		Iss, Scope, Aud string
+		Iat, Exp        int64
+	}
+	for i, v := range []interface{}{&header, &claims} {
+		d, err := enc.DecodeString(parts[i])
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(d, v); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case header.Alg != "RS256" || header.Typ != "JWT" || header.Kid != "key-id":
+		return fmt.Errorf("got header %+v", header)
+	case claims.Iss != email:
+		return fmt.Errorf("got iss %q, want %q", claims.Iss, email)
+	case claims.Scope != scope:
+		return fmt.Errorf("got scope %q, want %q", claims.Scope, scope)
+	case claims.Aud != aud:
+		return fmt.Errorf("got aud %q, want %q", claims.Aud, aud)
+	case claims.Exp-claims.Iat != 3600:
+		return fmt.Errorf("assertion lasts %ds, want 3600s", claims.Exp-claims.Iat)
+	}
+	return nil
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const email = "sa@example.iam.gserviceaccount.com"
+
+	tests := []struct {
+		name       string
+		scopes     []string
+		expiresIn  int
+		wantTokens []string // tokens used by two consecutive requests
+		wantFetch  int32
+		wantExpiry time.Duration
+	}{
+		{
+			name:       "cached",
+			expiresIn:  3600,
+			wantTokens: []string{"token-1", "token-1"},
+			wantFetch:  1,
+			wantExpiry: time.Hour,
+		},
+		{
+			name:       "near expiry",
+			expiresIn:  30,
+			wantTokens: []string{"token-1", "token-2"},
+			wantFetch:  2,
+			wantExpiry: 30 * time.Second,
+		},
+		{
+			name:       "missing expires_in",
+			wantTokens: []string{"token-1", "token-1"},
+			wantFetch:  1,
+			wantExpiry: time.Hour,
+		},
+		{
+			name:       "scopes",
+			scopes:     []string{ScopeCloudLanguage, "https://www.googleapis.com/auth/cloud-platform"},
+			expiresIn:  3600,
+			wantTokens: []string{"token-1", "token-1"},
+			wantFetch:  1,
+			wantExpiry: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := ScopeCloudLanguage
+			if len(tt.scopes) > 0 {
+				scope = strings.Join(tt.scopes, " ")
+			}
+
+			var n int32
+			srv := newTokenServer(t, &key.PublicKey, email, scope, tt.expiresIn, &n)
+			defer srv.Close()
+
+			sa := &ServiceAccount{
+				Email:        email,
+				PrivateKey:   key,
+				PrivateKeyID: "key-id",
+				TokenURL:     srv.URL + "/token",
+				Scopes:       tt.scopes,
+				HTTPClient:   srv.Client(),
+			}
+
+			start := time.Now()
+			for i, want := range tt.wantTokens {
+				r, _ := http.NewRequest("POST", "https://language.googleapis.com", nil)
+				if err := sa.Authorize(context.Background(), r); err != nil {
+					t.Fatal(err)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer "+want {
+					t.Errorf("request %d: got Authorization %q, want %q", i, got, "Bearer "+want)
+				}
+			}
+
+			if n != tt.wantFetch {
+				t.Errorf("got %d token requests, want %d", n, tt.wantFetch)
+			}
+			if d := sa.expiry.Sub(start); d < tt.wantExpiry || d > tt.wantExpiry+time.Minute {
+				t.Errorf("token expires after %v, want %v", d, tt.wantExpiry)
+			}
+		})
+	}
+}
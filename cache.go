@@ -0,0 +1,217 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Cache stores API responses by key. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	// Get returns the value stored for key, if any and not expired.
+	Get(key string) (value []byte, ok bool)
+
+	// Set stores value for key.
+	Set(key string, value []byte)
+}
+
+// CacheKey returns the key a Client caches the response of a request under. It
+// is a hash of the method, the API version and the JSON request body, which
+// contains the encoding, the document type, language and content, and any
+// other option of the request. Documents stored in Google Cloud Storage are
+// keyed by URI, so changes to the object are not noticed until the cached
+// response expires.
+func CacheKey(method string, version Version, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(version))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CacheStats are the cache counters of a Client.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// CacheStats returns the number of requests answered from the Cache of the
+// client and the number of requests sent to the API because of a cache miss.
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadInt64(&c.cacheHits),
+		Misses: atomic.LoadInt64(&c.cacheMisses),
+	}
+}
+
+// A MemoryCache is an in-memory Cache that evicts the least recently used
+// entries once full.
+type MemoryCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding up to size entries, each
+// expiring after ttl. A size of zero means the number of entries is not
+// limited, and a ttl of zero means entries never expire.
+func NewMemoryCache(size int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get satisfies the Cache interface for MemoryCache.
+func (mc *MemoryCache) Get(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	el, ok := mc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		mc.lru.Remove(el)
+		delete(mc.entries, key)
+		return nil, false
+	}
+
+	mc.lru.MoveToFront(el)
+	return e.value, true
+}
+
+// Set satisfies the Cache interface for MemoryCache.
+func (mc *MemoryCache) Set(key string, value []byte) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	var expires time.Time
+	if mc.ttl > 0 {
+		expires = time.Now().Add(mc.ttl)
+	}
+
+	if el, ok := mc.entries[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.value, e.expires = value, expires
+		mc.lru.MoveToFront(el)
+		return
+	}
+
+	mc.entries[key] = mc.lru.PushFront(&memoryEntry{key, value, expires})
+	for mc.size > 0 && mc.lru.Len() > mc.size {
+		el := mc.lru.Back()
+		mc.lru.Remove(el)
+		delete(mc.entries, el.Value.(*memoryEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// not evicted yet.
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.lru.Len()
+}
+
+// A DiskCache is a Cache storing each entry in a file of a directory. Entries
+// expire based on the modification time of their file.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewDiskCache returns a DiskCache storing entries in dir, which is created if
+// needed. Entries expire after ttl. A ttl of zero means entries never expire.
+func NewDiskCache(dir string, ttl time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir, ttl}, nil
+}
+
+func (dc *DiskCache) path(key string) string {
+	// Keys are not necessarily safe file names
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get satisfies the Cache interface for DiskCache.
+func (dc *DiskCache) Get(key string) ([]byte, bool) {
+	path := dc.path(key)
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if dc.ttl > 0 && time.Since(fi.ModTime()) > dc.ttl {
+		os.Remove(path)
+		return nil, false
+	}
+
+	value, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set satisfies the Cache interface for DiskCache. Errors are ignored since a
+// failure to cache a response must not fail the request.
+func (dc *DiskCache) Set(key string, value []byte) {
+	// Write to a temporary file first so that readers never see partial
+	// entries
+	f, err := ioutil.TempFile(dc.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if err = os.Rename(f.Name(), dc.path(key)); err != nil {
+		os.Remove(f.Name())
+	}
+}
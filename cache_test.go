@@ -0,0 +1,193 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+func TestClientCache(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	c := srv.Client()
+	c.Cache = gcnl.NewMemoryCache(0, 0)
+	req := entities.NewClientRequest(c)
+
+	doc := gcnl.NewPlainTextDocument("Larry Page founded Google.")
+	spanish, err := gcnl.WithLanguage(doc, "es")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name    string
+		req     *entities.Request
+		doc     gcnl.Document
+		wantHit bool
+	}{
+		{"first request", req, doc, false},
+		{"same document", req, doc, true},
+		{"other encoding", req.WithEncoding(gcnl.EncodingUTF16), doc, false},
+		{"other language", req, spanish, false},
+		{"same language", req, spanish, true},
+	}
+
+	var want gcnl.CacheStats
+	for _, step := range steps {
+		entityMap, err := step.req.FromDocument(step.doc)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if es := entityMap.Entities(); len(es) != 2 {
+			t.Errorf("%s: got entities %+v, want 2", step.name, es)
+		}
+
+		if step.wantHit {
+			want.Hits++
+		} else {
+			want.Misses++
+		}
+		if got := c.CacheStats(); got != want {
+			t.Errorf("%s: got stats %+v, want %+v", step.name, got, want)
+		}
+		if n := len(srv.Requests()); int64(n) != want.Misses {
+			t.Errorf("%s: sent %d requests, want %d", step.name, n, want.Misses)
+		}
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	mc := gcnl.NewMemoryCache(2, 0)
+	mc.Set("a", []byte("1"))
+	mc.Set("b", []byte("2"))
+	mc.Get("a")              // b is now the least recently used
+	mc.Set("c", []byte("3")) // evicts b
+	mc.Set("a", []byte("4")) // updates a, evicting nothing
+
+	for _, tt := range []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"a", "4", true},
+		{"b", "", false},
+		{"c", "3", true},
+	} {
+		value, ok := mc.Get(tt.key)
+		if ok != tt.wantOK || string(value) != tt.want {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, value, ok, tt.want, tt.wantOK)
+		}
+	}
+	if n := mc.Len(); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+
+	unlimited := gcnl.NewMemoryCache(0, 0)
+	for i := 0; i < 100; i++ {
+		unlimited.Set(string(rune('a'+i)), nil)
+	}
+	if n := unlimited.Len(); n != 100 {
+		t.Errorf("got %d entries in unlimited cache, want 100", n)
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	mc := gcnl.NewMemoryCache(0, time.Millisecond)
+	mc.Set("a", []byte("1"))
+	time.Sleep(5 * time.Millisecond)
+	mc.Set("b", []byte("2"))
+
+	if _, ok := mc.Get("a"); ok {
+		t.Error("got expired entry")
+	}
+	if n := mc.Len(); n != 1 {
+		t.Errorf("got %d entries, want expired entry removed", n)
+	}
+
+	mc = gcnl.NewMemoryCache(0, time.Hour)
+	mc.Set("a", []byte("1"))
+	if value, ok := mc.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Get = %q, %v, want unexpired entry", value, ok)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	dc, err := gcnl.NewDiskCache(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys are not file names
+	keys := []string{"a", "../b", "c/d"}
+	for i, key := range keys {
+		dc.Set(key, []byte{byte('0' + i)})
+	}
+	dc.Set("a", []byte("new"))
+
+	for i, want := range []string{"new", "1", "2"} {
+		if value, ok := dc.Get(keys[i]); !ok || string(value) != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", keys[i], value, ok, want)
+		}
+	}
+	if _, ok := dc.Get("missing"); ok {
+		t.Error("got missing entry")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(keys) {
+		t.Fatalf("got %d files, want %d", len(files), len(keys))
+	}
+
+	// Age every entry past the ttl
+	old := time.Now().Add(-2 * time.Hour)
+	for _, f := range files {
+		if err := os.Chtimes(filepath.Join(dir, f.Name()), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without a ttl, old entries are still used
+	forever, err := gcnl.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := forever.Get("a"); !ok {
+		t.Error("entry expired without a ttl")
+	}
+
+	if _, ok := dc.Get("a"); ok {
+		t.Error("got expired entry")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != len(keys)-1 {
+		t.Errorf("got %d files, want expired entry removed", len(files))
+	}
+}
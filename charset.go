@@ -0,0 +1,146 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var ErrUnsupportedCharset = errors.New("gcnl: unsupported charset")
+
+// A CharsetReader returns a reader converting input from the given charset to
+// UTF-8. It has the same signature as golang.org/x/net/html/charset.NewReaderLabel
+// so that package can be used to support more charsets.
+type CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+// metaCharset matches the charset declared by an HTML meta tag.
+var metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_:.\-]+)`)
+
+// htmlCharset returns the charset of an HTML document declared either in the
+// Content-Type header or in a meta tag within the first 1024 bytes, or an
+// empty string if none is declared.
+func htmlCharset(contentType string, content []byte) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if cs := params["charset"]; len(cs) > 0 {
+			return strings.ToLower(cs)
+		}
+	}
+
+	if len(content) > 1024 {
+		content = content[:1024]
+	}
+	if m := metaCharset.FindSubmatch(content); m != nil {
+		cs := strings.ToLower(string(m[1]))
+		// A meta tag readable as ASCII cannot be in a UTF-16 document, so
+		// per the HTML standard UTF-16 labels are taken to mean UTF-8
+		if strings.HasPrefix(cs, "utf-16") {
+			cs = "utf-8"
+		}
+		return cs
+	}
+
+	return ""
+}
+
+// decodeCharset converts content from the given charset to UTF-8. A byte order
+// mark takes precedence over charset. Charsets other than UTF-8, UTF-16,
+// US-ASCII, ISO-8859-1 and Windows-1252 require cr. Invalid UTF-8 sequences
+// are replaced with U+FFFD.
+func decodeCharset(charset string, content []byte, cr CharsetReader) (string, error) {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		charset, content = "utf-8", content[3:]
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		charset, content = "utf-16le", content[2:]
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		charset, content = "utf-16be", content[2:]
+	}
+
+	switch charset {
+	case "", "utf-8", "utf8", "unicode-1-1-utf-8":
+		return strings.ToValidUTF8(string(content), "\uFFFD"), nil
+
+	case "utf-16le", "utf-16", "utf-16be":
+		return decodeUTF16(content, charset == "utf-16be"), nil
+
+	// Per the WHATWG encoding standard, US-ASCII and ISO-8859-1 labels are
+	// decoded as Windows-1252
+	case "us-ascii", "ascii", "iso-8859-1", "iso8859-1", "latin1", "l1",
+		"windows-1252", "cp1252", "x-cp1252":
+		return decodeWindows1252(content), nil
+	}
+
+	if cr == nil {
+		return "", fmt.Errorf("%w %q", ErrUnsupportedCharset, charset)
+	}
+
+	r, err := cr(charset, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	d, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToValidUTF8(string(d), "\uFFFD"), nil
+}
+
+func decodeUTF16(content []byte, bigEndian bool) string {
+	u := make([]uint16, len(content)/2)
+	for i := range u {
+		if bigEndian {
+			u[i] = uint16(content[2*i])<<8 | uint16(content[2*i+1])
+		} else {
+			u[i] = uint16(content[2*i+1])<<8 | uint16(content[2*i])
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+// windows1252 maps bytes 0x80 to 0x9F of Windows-1252 to runes. The other
+// bytes map to the rune of the same value.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+func decodeWindows1252(content []byte) string {
+	buf := make([]byte, 0, len(content))
+	for _, b := range content {
+		switch {
+		case b < 0x80:
+			buf = append(buf, b)
+		case b < 0xA0:
+			buf = utf8.AppendRune(buf, windows1252[b-0x80])
+		default:
+			buf = utf8.AppendRune(buf, rune(b))
+		}
+	}
+	return string(buf)
+}
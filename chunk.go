@@ -0,0 +1,124 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Split splits content into pieces of at most maxSize bytes, so that documents
+// above the API size limit can be analyzed in several requests. Pieces end at
+// the last paragraph boundary that fits, else at the last sentence boundary,
+// else at the last whitespace, and only split words that are longer than
+// maxSize. The concatenation of the pieces is content. If maxSize is not
+// positive, DefaultMaxSize is used.
+func Split(content string, maxSize int) []string {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	var pieces []string
+	for len(content) > maxSize {
+		n := splitIndex(content, maxSize)
+		pieces = append(pieces, content[:n])
+		content = content[n:]
+	}
+	if len(content) > 0 {
+		pieces = append(pieces, content)
+	}
+	return pieces
+}
+
+// splitIndex returns the length of the first piece of content, which is longer
+// than maxSize.
+func splitIndex(content string, maxSize int) int {
+	// Never split a character
+	n := maxSize
+	for n > 0 && !utf8.RuneStart(content[n]) {
+		n--
+	}
+	if n == 0 {
+		_, size := utf8.DecodeRuneInString(content)
+		return size
+	}
+	window := content[:n]
+
+	if i := strings.LastIndex(window, "\n\n"); i > 0 {
+		return i + 2
+	}
+
+	sentence := 0
+	for i, r := range window {
+		switch r {
+		case '\n':
+			sentence = i + 1
+		case '.', '!', '?':
+			if i+1 < len(window) && isSpace(window[i+1]) {
+				sentence = i + 2
+			}
+		case '。', '！', '？':
+			sentence = i + utf8.RuneLen(r)
+		}
+	}
+	if sentence > 0 {
+		return sentence
+	}
+
+	if i := strings.LastIndexAny(window, " \t\r\n"); i >= 0 {
+		return i + 1
+	}
+	return n
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// A Chunk is a document holding a piece of the content of a larger document.
+type Chunk struct {
+	Document
+
+	// Offset is the byte offset of the chunk in the content of the original
+	// document.
+	Offset int
+}
+
+// SplitDocument splits the content of doc using Split. Each chunk has the type
+// and language of doc. Documents referring to Google Cloud Storage, whose
+// content is not known, are returned as a single chunk. Note that splitting an
+// HTML document may break its markup.
+func SplitDocument(doc Document, maxSize int) []Chunk {
+	if gcs, ok := doc.(gcsDocument); ok && len(gcs.GCSContentURI()) > 0 {
+		return []Chunk{{doc, 0}}
+	}
+
+	var chunks []Chunk
+	offset := 0
+	for _, piece := range Split(doc.Content(), maxSize) {
+		var d Document
+		if doc.Type() == TypeHTML {
+			d = &HTMLDocument{piece, doc.Language()}
+		} else {
+			d = &PlainTextDocument{piece, doc.Language()}
+		}
+		chunks = append(chunks, Chunk{d, offset})
+		offset += len(piece)
+	}
+	return chunks
+}
@@ -0,0 +1,86 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		maxSize int
+		want    []string
+	}{
+		{"Short.", 100, []string{"Short."}},
+		{"", 100, nil},
+		{"One. Two.\n\nThree. Four.", 20, []string{"One. Two.\n\n", "Three. Four."}},
+		{"One. Two. Three. Four.", 11, []string{"One. Two. ", "Three. ", "Four."}},
+		{"One two three four", 9, []string{"One two ", "three ", "four"}},
+		{"abcdefgh", 3, []string{"abc", "def", "gh"}},
+		{"ééé", 3, []string{"é", "é", "é"}},
+		{"😀", 2, []string{"😀"}},
+		{"Un. Deux。Trois", 12, []string{"Un. Deux。", "Trois"}},
+	} {
+		got := Split(tt.content, tt.maxSize)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Split(%q, %d) = %q, want %q", tt.content, tt.maxSize, got, tt.want)
+		}
+	}
+}
+
+func TestSplitLimits(t *testing.T) {
+	content := strings.Repeat("Larry Page founded Google in California. Ünïcödé wörds! ", 500)
+	for _, maxSize := range []int{7, 50, 333, 4096} {
+		pieces := Split(content, maxSize)
+		if strings.Join(pieces, "") != content {
+			t.Fatalf("Split(%d) lost content", maxSize)
+		}
+		for _, p := range pieces {
+			if len(p) > maxSize || !utf8.ValidString(p) {
+				t.Fatalf("Split(%d) returned invalid piece %q", maxSize, p)
+			}
+		}
+	}
+}
+
+func TestSplitDocument(t *testing.T) {
+	doc, _ := WithLanguage(&HTMLDocument{"<p>One.</p>\n\n<p>Two.</p>", LanguageEnglish}, "fr")
+	chunks := SplitDocument(doc, 15)
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	for _, c := range chunks {
+		if c.Type() != TypeHTML || c.Language() != "fr" {
+			t.Errorf("chunk is %s in %q, want HTML in fr", c.Type(), c.Language())
+		}
+		if doc.Content()[c.Offset:c.Offset+len(c.Content())] != c.Content() {
+			t.Errorf("chunk %q is not at offset %d", c.Content(), c.Offset)
+		}
+	}
+
+	gcs, err := NewGCSDocument("gs://bucket/object", TypePlainText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunks := SplitDocument(gcs, 1); len(chunks) != 1 || chunks[0].Document != gcs {
+		t.Errorf("GCS document was split into %+v", chunks)
+	}
+}
@@ -0,0 +1,181 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package classify
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jlubawy/go-gcnl"
+)
+
+// Method is the name of the API method used by requests.
+const Method = "classifyText"
+
+// Endpoint is the URL of the method when using the default Client settings.
+// classifyText is only available starting with v1beta2.
+const Endpoint = "https://language.googleapis.com/v1beta2/documents:classifyText"
+
+var (
+	ErrMissingKey = gcnl.ErrMissingKey
+	ErrNoClient   = gcnl.ErrNoClient
+)
+
+// A Category is a content category from the API taxonomy, for example
+// "/Science/Computer Science". Confidence ranges from 0.0 to 1.0.
+type Category struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Levels splits the category name into its hierarchy levels, from the most
+// general to the most specific. "/Science/Computer Science" returns
+// []string{"Science", "Computer Science"}.
+func (c Category) Levels() []string {
+	name := strings.Trim(c.Name, "/")
+	if len(name) == 0 {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
+
+// Depth returns the number of hierarchy levels of the category.
+func (c Category) Depth() int {
+	return len(c.Levels())
+}
+
+// Categories is a slice of categories as returned by the API.
+type Categories []Category
+
+// FilterConfidence returns the categories with a confidence of at least min.
+func (cs Categories) FilterConfidence(min float64) Categories {
+	var filtered Categories
+	for _, c := range cs {
+		if c.Confidence >= min {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Level returns the distinct names of the categories at the given hierarchy
+// level, starting at 0, in the order they first appear. Categories not deep
+// enough are skipped.
+func (cs Categories) Level(level int) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, c := range cs {
+		levels := c.Levels()
+		if level < 0 || level >= len(levels) || seen[levels[level]] {
+			continue
+		}
+		seen[levels[level]] = true
+		names = append(names, levels[level])
+	}
+	return names
+}
+
+// A Request sends documents to the classify API. It must be created using
+// NewRequest or NewClientRequest; the zero value fails with ErrNoClient. A
+// Request is safe for concurrent use and may be reused for any number of
+// documents.
+type Request struct {
+	client *gcnl.Client
+}
+
+// requestBody is the JSON object sent to the classify API.
+type requestBody struct {
+	Doc gcnl.Document `json:"document"`
+}
+
+// Document satisfies the gcnl.DocumentRequest interface for requestBody.
+func (body *requestBody) Document() gcnl.Document {
+	return body.Doc
+}
+
+// NewRequest returns a Request object with the given API key.
+func NewRequest(key string) *Request {
+	return NewClientRequest(gcnl.NewClient(key))
+}
+
+// NewClientRequest returns a Request object sent using the given Client.
+func NewClientRequest(c *gcnl.Client) *Request {
+	return &Request{
+		client: c,
+	}
+}
+
+// FromURL classifies the content retrieved from a given URL. It expects the
+// content retrieved from URL to be valid HTML.
+func (req *Request) FromURL(url string) (cs Categories, err error) {
+	return req.FromURLContext(context.Background(), url)
+}
+
+// FromURLContext is like FromURL but uses ctx for both fetching the URL, using
+// the Fetcher of the client, and the API request.
+func (req *Request) FromURLContext(ctx context.Context, url string) (cs Categories, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+	doc, err := req.client.FetchHTML(ctx, url)
+	if err != nil {
+		return
+	}
+	return req.FromDocumentContext(ctx, doc)
+}
+
+// FromPlainText classifies the given plain text.
+func (req *Request) FromPlainText(content string) (cs Categories, err error) {
+	return req.FromPlainTextContext(context.Background(), content)
+}
+
+// FromPlainTextContext is like FromPlainText but uses ctx for the API request.
+func (req *Request) FromPlainTextContext(ctx context.Context, content string) (cs Categories, err error) {
+	return req.FromDocumentContext(ctx, gcnl.NewPlainTextDocument(content))
+}
+
+// FromDocument classifies the given document.
+func (req *Request) FromDocument(doc gcnl.Document) (cs Categories, err error) {
+	return req.FromDocumentContext(context.Background(), doc)
+}
+
+// FromDocumentContext is like FromDocument but uses ctx for the API request.
+func (req *Request) FromDocumentContext(ctx context.Context, doc gcnl.Document) (cs Categories, err error) {
+	return req.do(ctx, doc)
+}
+
+// Do makes the actual API request for a given document.
+func (req *Request) do(ctx context.Context, doc gcnl.Document) (cs Categories, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+
+	var jsonResp struct {
+		Categories Categories `json:"categories"`
+	}
+
+	err = req.client.DoContext(ctx, Method, &requestBody{Doc: doc}, &jsonResp)
+	if err != nil {
+		return
+	}
+
+	cs = jsonResp.Categories
+	return
+}
@@ -0,0 +1,80 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package classify_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl/classify"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+func TestZeroRequest(t *testing.T) {
+	var req classify.Request
+	if _, err := req.FromPlainText("Larry Page founded Google."); err != classify.ErrNoClient {
+		t.Errorf("got error %v, want %v", err, classify.ErrNoClient)
+	}
+}
+
+func TestRequestConcurrentReuse(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+
+	want := classify.Categories{{Name: "/Science/Computer Science", Confidence: 0.9}}
+	for i := 0; i < 3; i++ {
+		srv.RespondJSON(classify.Method, map[string]interface{}{"categories": want})
+	}
+
+	req := classify.NewClientRequest(srv.Client())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cs, err := req.FromPlainText("Computers compute things.")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(cs, want) {
+				t.Errorf("got %+v, want %+v", cs, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCategoriesLevel(t *testing.T) {
+	cs := classify.Categories{
+		{Name: "/Science/Computer Science", Confidence: 0.9},
+		{Name: "/Science/Physics", Confidence: 0.4},
+		{Name: "/Arts & Entertainment", Confidence: 0.2},
+	}
+	if got, want := cs.Level(0), []string{"Science", "Arts & Entertainment"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Level(0) = %q, want %q", got, want)
+	}
+	if got, want := cs.Level(1), []string{"Computer Science", "Physics"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Level(1) = %q, want %q", got, want)
+	}
+	if got := cs.FilterConfidence(0.5); len(got) != 1 || got[0].Depth() != 2 {
+		t.Errorf("FilterConfidence(0.5) = %+v", got)
+	}
+}
@@ -0,0 +1,316 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBaseURL is the base URL of the Google Cloud Natural Language API.
+const DefaultBaseURL = "https://language.googleapis.com"
+
+// A Version specifies the API version used by a Client.
+type Version string
+
+const (
+	VersionV1Beta1 Version = "v1beta1"
+	VersionV1Beta2         = "v1beta2"
+	VersionV1              = "v1"
+
+	// Default to v1beta1
+	VersionDefault = VersionV1Beta1
+)
+
+// versions lists the supported versions from oldest to newest.
+var versions = []Version{VersionV1Beta1, VersionV1Beta2, VersionV1}
+
+// minVersions lists the methods not available in every version.
+var minVersions = map[string]Version{
+	"analyzeEntitySentiment": VersionV1Beta2,
+	"analyzeSyntax":          VersionV1Beta2,
+	"classifyText":           VersionV1Beta2,
+}
+
+func versionIndex(v Version) int {
+	for i := range versions {
+		if versions[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+var ErrMissingKey = errors.New("must provide an API key")
+
+// ErrNoClient is returned by requests of the method packages that were not
+// created using their NewRequest or NewClientRequest functions.
+var ErrNoClient = errors.New("gcnl: request has no client, create it using NewRequest or NewClientRequest")
+
+// A Client sends requests to the API. The zero value of each field other than
+// Key selects its default, so a Client may also be created as a struct literal.
+// A Client is safe for concurrent use once configured.
+type Client struct {
+	// Accessed atomically, first in the struct to be 64-bit aligned on
+	// 32-bit platforms.
+	cacheHits   int64
+	cacheMisses int64
+
+	// Key is the API key sent with every request when Credentials is nil.
+	Key string
+
+	// Credentials, if not nil, authenticate requests instead of Key.
+	Credentials Credentials
+
+	// HTTPClient is used to send requests. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+
+	// BaseURL is the URL requests are sent to. If empty, DefaultBaseURL is
+	// used.
+	BaseURL string
+
+	// Cache, if not nil, stores successful responses so that identical
+	// requests are answered without calling the API. See CacheKey.
+	Cache Cache
+
+	// Fetcher retrieves the documents of FromURL requests. If nil,
+	// DefaultFetcher is used.
+	Fetcher *Fetcher
+
+	// Limiter, if not nil, throttles every attempt at a request, including
+	// retries.
+	Limiter *Limiter
+
+	// Retry controls how failed requests are retried. If nil, requests are
+	// not retried.
+	Retry *RetryPolicy
+
+	// Version is the API version used for requests. If empty, VersionDefault
+	// is used. Methods that do not exist in Version use the oldest version
+	// that supports them instead.
+	Version Version
+}
+
+// NewClient returns a Client using the given API key and default settings.
+func NewClient(key string) *Client {
+	return &Client{
+		Key:     key,
+		BaseURL: DefaultBaseURL,
+		Version: VersionDefault,
+	}
+}
+
+// String redacts the API key when the client is printed. Credentials are
+// printed using their own String method, which redacts the credentials of this
+// package.
+func (c *Client) String() string {
+	key := ""
+	if len(c.Key) > 0 {
+		key = "REDACTED"
+	}
+	creds := "<nil>"
+	if c.Credentials != nil {
+		creds = fmt.Sprint(c.Credentials)
+	}
+	return fmt.Sprintf("gcnl.Client{Key:%s, Credentials:%s, BaseURL:%q, Version:%q}", key, creds, c.BaseURL, c.Version)
+}
+
+// GoString is like String, for the %#v verb.
+func (c *Client) GoString() string {
+	return c.String()
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c *Client) credentials() Credentials {
+	if c.Credentials == nil {
+		return APIKey(c.Key)
+	}
+	return c.Credentials
+}
+
+// FetchHTML retrieves the HTML document at url using the Fetcher of the
+// client.
+func (c *Client) FetchHTML(ctx context.Context, url string) (Document, error) {
+	f := c.Fetcher
+	if f == nil {
+		f = DefaultFetcher
+	}
+	return f.Fetch(ctx, url)
+}
+
+// VersionFor returns the API version used for the given method, for example
+// "analyzeEntities".
+func (c *Client) VersionFor(method string) Version {
+	v := c.Version
+	if len(v) == 0 {
+		v = VersionDefault
+	}
+	if min, ok := minVersions[method]; ok && versionIndex(v) < versionIndex(min) {
+		v = min
+	}
+	return v
+}
+
+// Endpoint returns the URL of the given method, for example
+// "analyzeEntities".
+func (c *Client) Endpoint(method string) string {
+	base := c.BaseURL
+	if len(base) == 0 {
+		base = DefaultBaseURL
+	}
+	return fmt.Sprintf("%s/%s/documents:%s", strings.TrimSuffix(base, "/"), c.VersionFor(method), method)
+}
+
+// Do serializes body into JSON and sends it to the given method, for example
+// "analyzeEntities". The JSON response is decoded into v.
+func (c *Client) Do(method string, body, v interface{}) error {
+	return c.DoContext(context.Background(), method, body, v)
+}
+
+// DoContext is like Do but sends the request using ctx. If ctx is done before
+// the response is decoded ctx.Err() is returned.
+func (c *Client) DoContext(ctx context.Context, method string, body, v interface{}) (err error) {
+	if c.Credentials == nil && len(c.Key) == 0 {
+		err = ErrMissingKey
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	d, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+
+	var key string
+	if c.Cache != nil {
+		key = CacheKey(method, c.VersionFor(method), d)
+		if data, ok := c.Cache.Get(key); ok {
+			atomic.AddInt64(&c.cacheHits, 1)
+			return json.Unmarshal(data, v)
+		}
+		atomic.AddInt64(&c.cacheMisses, 1)
+	}
+
+	units := 1
+	if dr, ok := body.(DocumentRequest); ok && dr.Document() != nil {
+		units = TextUnits(dr.Document())
+	}
+
+	for attempt := 1; ; attempt++ {
+		if c.Limiter != nil {
+			if err = c.Limiter.Wait(ctx, units); err != nil {
+				return
+			}
+		}
+
+		var data []byte
+		var retryable bool
+		data, retryable, err = c.send(ctx, method, d)
+		if err == nil {
+			if err = json.Unmarshal(data, v); err == nil && c.Cache != nil {
+				c.Cache.Set(key, data)
+			}
+			return
+		}
+		if !retryable {
+			return
+		}
+
+		delay, ok := c.Retry.delay(attempt, err)
+		if !ok {
+			return
+		}
+		if c.Retry.OnRetry != nil {
+			c.Retry.OnRetry(RetryInfo{
+				Method:  method,
+				Attempt: attempt,
+				Err:     err,
+				Delay:   delay,
+			})
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			err = ctx.Err()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// send makes a single attempt at a request and returns the response body.
+// retryable reports whether the request failed in a way that may succeed if
+// sent again: a network error or a temporary API error.
+func (c *Client) send(ctx context.Context, method string, body []byte) (data []byte, retryable bool, err error) {
+	r, err := http.NewRequestWithContext(ctx, "POST", c.Endpoint(method), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	if err = c.credentials().Authorize(ctx, r); err != nil {
+		return
+	}
+
+	resp, err := c.httpClient().Do(r)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+		retryable = isNetworkError(err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(method, resp)
+		retryable = apiErr.IsTemporary()
+		err = apiErr
+		return
+	}
+
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+		retryable = isNetworkError(err)
+	}
+	return
+}
@@ -42,7 +42,7 @@ var Options struct {
 	Port string
 }
 
-var apiKey string
+var client *gcnl.Client
 var t = make(map[string]*template.Template)
 
 func init() {
@@ -50,11 +50,12 @@ func init() {
 	flag.Parse()
 
 	// Get the API key
-	apiKey = os.Getenv("GOOGLE_API_KEY")
+	apiKey := os.Getenv("GOOGLE_API_KEY")
 	if len(apiKey) == 0 {
 		fmt.Fprintln(os.Stderr, "must set GOOGLE_API_KEY environment variable")
 		os.Exit(1)
 	}
+	client = gcnl.NewClient(apiKey)
 
 	// Initialize templates
 	path := "data/templ"
@@ -126,8 +127,8 @@ func HandleIndex(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		req := entities.NewRequest(apiKey)
-		entityMap, err := req.FromPlainText(content)
+		doc := gcnl.NewPlainTextDocument(content)
+		entityMap, err := entities.NewClientRequest(client).FromDocument(doc)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -137,7 +138,7 @@ func HandleIndex(w http.ResponseWriter, r *http.Request) {
 		jsonResp := struct {
 			HTML string `json:"html"`
 		}{
-			AnnotateDocument(req.Document(), entityMap),
+			AnnotateDocument(doc, entityMap),
 		}
 
 		// Return JSON response
@@ -0,0 +1,164 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jlubawy/go-gcnl"
+)
+
+// A Result is the outcome of analyzing one document, either alone using
+// Analyze or as part of a batch.
+type Result struct {
+	Index    int // position of the document in the input
+	Document gcnl.Document
+	Map      Map
+	Language string
+	Err      error
+}
+
+// analyze analyzes the document at index i of a batch.
+func (req *Request) analyze(ctx context.Context, i int, doc gcnl.Document) Result {
+	res := req.Analyze(ctx, doc)
+	res.Index = i
+	return res
+}
+
+// Batch analyzes docs using up to workers concurrent requests. All requests
+// share the client of req, including its Limiter and Retry policy. Results are
+// returned in the order of docs, and a failed document does not stop the
+// others from being analyzed. Documents not analyzed before ctx is done fail
+// with ctx.Err().
+func (req *Request) Batch(ctx context.Context, docs []gcnl.Document, workers int) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(docs))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = req.analyze(ctx, i, docs[i])
+			}
+		}()
+	}
+
+	for i := range docs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// BatchChan is like Batch but reads the documents from docs until it is
+// closed. Results are sent on the returned channel in the order the documents
+// were received, which is closed once every result has been sent. Results
+// completed ahead of a slow document are buffered until it completes.
+//
+// The caller must either receive every result or cancel ctx. Once ctx is done,
+// BatchChan stops reading docs, drops the results not received yet and closes
+// the returned channel after all of its goroutines have stopped.
+func (req *Request) BatchChan(ctx context.Context, docs <-chan gcnl.Document, workers int) <-chan Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		i   int
+		doc gcnl.Document
+	}
+
+	jobs := make(chan job)
+	done := make(chan Result)
+	out := make(chan Result)
+
+	// Number the documents as they are received
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			select {
+			case doc, ok := <-docs:
+				if !ok {
+					return
+				}
+				select {
+				case jobs <- job{i, doc}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				done <- req.analyze(ctx, j.i, j.doc)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Restore the input order
+	go func() {
+		defer close(out)
+		// Let the workers finish if the results are no longer wanted
+		defer func() {
+			for range done {
+			}
+		}()
+
+		pending := make(map[int]Result)
+		next := 0
+		for res := range done {
+			pending[res.Index] = res
+			for {
+				r, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					return
+				}
+				next++
+			}
+		}
+	}()
+
+	return out
+}
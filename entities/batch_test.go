@@ -0,0 +1,225 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/entities"
+)
+
+// newBatchServer returns a server answering analyzeEntities with the third
+// word of the document as the only entity. Documents are answered more slowly
+// the earlier their letter in the alphabet, so that batches complete out of
+// order. Documents containing "fail" are rejected.
+func newBatchServer(requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		var body struct {
+			Document struct {
+				Content string `json:"content"`
+			} `json:"document"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		words := strings.Fields(body.Document.Content)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body.Document.Content, "fail") || len(words) < 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":400,"message":"bad document","status":"INVALID_ARGUMENT"}}`)
+			return
+		}
+
+		name := words[2]
+		time.Sleep(time.Duration('Z'-name[len(name)-1]) * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entities": []entities.Entity{{Name: name, Type: entities.TypePerson, Salience: 1}},
+			"language": gcnl.LanguageEnglish,
+		})
+	}))
+}
+
+func newBatchClient(srv *httptest.Server) *gcnl.Client {
+	c := gcnl.NewClient("key")
+	c.BaseURL = srv.URL
+	return c
+}
+
+// batchDocs returns n documents each mentioning a distinct entity.
+func batchDocs(n int) (docs []gcnl.Document, names []string) {
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("Person%c", 'A'+i)
+		names = append(names, name)
+		docs = append(docs, gcnl.NewPlainTextDocument("I met "+name+" today."))
+	}
+	return
+}
+
+func checkResult(t *testing.T, res entities.Result, i int, doc gcnl.Document, name string) {
+	t.Helper()
+	if res.Index != i || res.Document != doc {
+		t.Errorf("result %d is for document %d", i, res.Index)
+		return
+	}
+	if res.Err != nil {
+		t.Errorf("result %d: %v", i, res.Err)
+		return
+	}
+	if es := res.Map.Entities(); len(es) != 1 || es[0].Name != name {
+		t.Errorf("result %d: got %+v, want %s", i, es, name)
+	}
+	if res.Language != gcnl.LanguageEnglish {
+		t.Errorf("result %d: got language %q", i, res.Language)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var requests int32
+	srv := newBatchServer(&requests)
+	defer srv.Close()
+
+	docs, names := batchDocs(12)
+	results := entities.NewClientRequest(newBatchClient(srv)).Batch(context.Background(), docs, 4)
+	if len(results) != len(docs) {
+		t.Fatalf("got %d results, want %d", len(results), len(docs))
+	}
+	for i, res := range results {
+		checkResult(t, res, i, docs[i], names[i])
+	}
+	if n := atomic.LoadInt32(&requests); n != int32(len(docs)) {
+		t.Errorf("sent %d requests, want %d", n, len(docs))
+	}
+}
+
+func TestBatchFailure(t *testing.T) {
+	var requests int32
+	srv := newBatchServer(&requests)
+	defer srv.Close()
+
+	docs, names := batchDocs(5)
+	docs[2] = gcnl.NewPlainTextDocument("This will fail.")
+	results := entities.NewClientRequest(newBatchClient(srv)).Batch(context.Background(), docs, 2)
+
+	for i, res := range results {
+		if i == 2 {
+			if !gcnl.IsInvalidArgument(res.Err) {
+				t.Errorf("got error %v, want invalid argument", res.Err)
+			}
+			continue
+		}
+		checkResult(t, res, i, docs[i], names[i])
+	}
+}
+
+func TestBatchCanceled(t *testing.T) {
+	var requests int32
+	srv := newBatchServer(&requests)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	docs, _ := batchDocs(3)
+	for _, res := range entities.NewClientRequest(newBatchClient(srv)).Batch(ctx, docs, 2) {
+		if res.Err != context.Canceled {
+			t.Errorf("result %d: got error %v, want %v", res.Index, res.Err, context.Canceled)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("sent %d requests, want 0", n)
+	}
+}
+
+func TestBatchChan(t *testing.T) {
+	var requests int32
+	srv := newBatchServer(&requests)
+	defer srv.Close()
+
+	docs, names := batchDocs(12)
+	in := make(chan gcnl.Document)
+	go func() {
+		for _, doc := range docs {
+			in <- doc
+		}
+		close(in)
+	}()
+
+	i := 0
+	for res := range entities.NewClientRequest(newBatchClient(srv)).BatchChan(context.Background(), in, 4) {
+		if i >= len(docs) {
+			t.Fatal("got too many results")
+		}
+		checkResult(t, res, i, docs[i], names[i])
+		i++
+	}
+	if i != len(docs) {
+		t.Errorf("got %d results, want %d", i, len(docs))
+	}
+}
+
+func TestBatchChanCanceled(t *testing.T) {
+	var requests int32
+	srv := newBatchServer(&requests)
+	defer srv.Close()
+
+	// The documents never run out, so only canceling stops the batch
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan gcnl.Document)
+	go func() {
+		for i := 0; ; i++ {
+			doc := gcnl.NewPlainTextDocument(fmt.Sprintf("I met Person%c today.", 'A'+i%26))
+			select {
+			case in <- doc:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := entities.NewClientRequest(newBatchClient(srv)).BatchChan(ctx, in, 4)
+	if res := <-out; res.Index != 0 || res.Err != nil {
+		t.Fatalf("got result %d with error %v", res.Index, res.Err)
+	}
+
+	// Stop reading without receiving the pending results
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("results not closed after canceling")
+		}
+	}
+}
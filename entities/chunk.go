@@ -0,0 +1,98 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jlubawy/go-gcnl"
+)
+
+// FromLargeDocument analyzes a document whose content may exceed the API size
+// limit. The content is split into chunks of at most maxSize bytes using
+// gcnl.SplitDocument, which are analyzed using up to workers concurrent
+// requests as by Batch. If maxSize is not positive, gcnl.DefaultMaxSize is
+// used.
+//
+// The offsets of the mentions are rebased to the content of doc, and entities
+// of the same type found in several chunks are merged as by Merge, except that
+// their salience is the average over the chunks weighted by chunk size. If any
+// chunk fails, its error is returned.
+func (req *Request) FromLargeDocument(ctx context.Context, doc gcnl.Document, maxSize, workers int) (entityMap Map, err error) {
+	chunks := gcnl.SplitDocument(doc, maxSize)
+	if len(chunks) <= 1 {
+		return req.AnalyzeEntities(ctx, doc)
+	}
+
+	docs := make([]gcnl.Document, len(chunks))
+	for i := range chunks {
+		docs[i] = chunks[i]
+	}
+
+	content := doc.Content()
+	maps := make([]Map, len(chunks))
+	for _, res := range req.Batch(ctx, docs, workers) {
+		if res.Err != nil {
+			err = fmt.Errorf("entities: chunk %d: %w", res.Index, res.Err)
+			return
+		}
+		if err = rebase(res.Map, content, chunks[res.Index].Offset, req.enc); err != nil {
+			return
+		}
+		maps[res.Index] = res.Map
+	}
+
+	entityMap = make(Map)
+	for _, g := range linkByType(maps...) {
+		e := mergeGroup(g)
+
+		// Weight the salience of each chunk by its share of the content
+		e.Salience = 0
+		for _, o := range g.Occurrences {
+			size := float64(len(chunks[o.Document].Content()))
+			e.Salience += o.Entity.Salience * size / float64(len(content))
+		}
+
+		entityMap[e.Type] = append(entityMap[e.Type], e)
+	}
+	entityMap.Sort()
+	return
+}
+
+// rebase shifts the offsets of the mentions of m, computed by the API using
+// enc within a chunk starting at byte offset of content, to offsets of content.
+func rebase(m Map, content string, offset int, enc gcnl.Encoding) error {
+	if enc == gcnl.EncodingNone || offset == 0 {
+		return nil
+	}
+	base, err := gcnl.EncodedOffset(content, offset, enc)
+	if err != nil {
+		return err
+	}
+	for _, es := range m {
+		for i := range es {
+			for j := range es[i].Mentions {
+				if es[i].Mentions[j].TextSpan.BeginOffset >= 0 {
+					es[i].Mentions[j].TextSpan.BeginOffset += base
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+func TestFromLargeDocument(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	content := strings.Repeat("Alice Smith met Bob Jones in Paris. It was great fun!\n\n", 20) + "Ünïcode Ärger visited Paris."
+	doc := gcnl.NewPlainTextDocument(content)
+
+	for _, enc := range []gcnl.Encoding{gcnl.EncodingUTF8, gcnl.EncodingUTF16, gcnl.EncodingUTF32} {
+		srv.Reset()
+		req := entities.NewClientRequest(srv.Client()).WithEncoding(enc)
+		m, err := req.FromLargeDocument(context.Background(), doc, 120, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(srv.Requests()); n < 2 {
+			t.Fatalf("%s: document was sent in %d requests", enc, n)
+		}
+
+		es := m.Entities()
+		if len(es) != 4 || es[0].Name != "Paris" || len(es[0].Mentions) != 21 {
+			t.Fatalf("%s: got entities %+v", enc, es)
+		}
+		for _, e := range es {
+			for _, mention := range e.Mentions {
+				text, err := mention.Text(doc)
+				if err != nil || text != mention.TextSpan.Content {
+					t.Errorf("%s: mention %+v covers %q (%v)", enc, mention.TextSpan, text, err)
+				}
+			}
+		}
+	}
+}
+
+func TestFromLargeDocumentTypes(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+
+	content := "Jordan is a person.\n\nJordan is a country."
+	srv.Respond(entities.Method, http.StatusOK, `{"entities":[{"name":"Jordan","type":"PERSON","salience":1,
+		"mentions":[{"text":{"content":"Jordan","beginOffset":0}}]}]}`)
+	srv.Respond(entities.Method, http.StatusOK, `{"entities":[{"name":"Jordan","type":"LOCATION","salience":1,
+		"mentions":[{"text":{"content":"Jordan","beginOffset":0}}]}]}`)
+
+	req := entities.NewClientRequest(srv.Client())
+	m, err := req.FromLargeDocument(context.Background(), gcnl.NewPlainTextDocument(content), 25, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m[entities.TypePerson]) != 1 || len(m[entities.TypeLocation]) != 1 {
+		t.Fatalf("got %+v, want a person and a location", m)
+	}
+	if offset := m[entities.TypeLocation][0].Mentions[0].TextSpan.BeginOffset; offset != 21 {
+		t.Errorf("location mention at offset %d, want 21", offset)
+	}
+}
+
+func TestFromLargeDocumentError(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.Fail(entities.Method, http.StatusBadRequest, 1)
+
+	req := entities.NewClientRequest(srv.Client())
+	_, err := req.FromLargeDocument(context.Background(), gcnl.NewPlainTextDocument("One.\n\nTwo."), 6, 1)
+	if !gcnl.IsInvalidArgument(err) {
+		t.Errorf("got error %v, want invalid argument", err)
+	}
+}
@@ -18,18 +18,31 @@
 package entities
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/http"
+	"context"
+	"sync/atomic"
 
 	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/sentiment"
 )
 
+// Method and SentimentMethod are the names of the API methods used by requests.
+const (
+	Method          = "analyzeEntities"
+	SentimentMethod = "analyzeEntitySentiment"
+)
+
+// Endpoint is the URL of Method when using the default Client settings.
 const Endpoint = "https://language.googleapis.com/v1beta1/documents:analyzeEntities"
 
-var ErrMissingKey = errors.New("must provide an API key")
+// SentimentEndpoint is the URL of SentimentMethod when using the default
+// Client settings. analyzeEntitySentiment is only available starting with
+// v1beta2.
+const SentimentEndpoint = "https://language.googleapis.com/v1beta2/documents:analyzeEntitySentiment"
+
+var (
+	ErrMissingKey = gcnl.ErrMissingKey
+	ErrNoClient   = gcnl.ErrNoClient
+)
 
 // An Entity represents a phrase is the text that is a known entity of a given Type.
 type Entity struct {
@@ -38,6 +51,10 @@ type Entity struct {
 	Metadata map[string]string `json:"metadata"`
 	Salience float64           `json:"salience"`
 	Mentions []Mention         `json:"mentions"`
+
+	// Sentiment is the aggregate sentiment expressed for the entity across
+	// all of its mentions. It is only set by a sentiment request.
+	Sentiment *sentiment.Sentiment `json:"sentiment,omitempty"`
 }
 
 // A Type specifies the valid entity types returned by the API.
@@ -57,100 +74,247 @@ const (
 // A Map is a map of Types to Entities.
 type Map map[Type][]Entity
 
+// NewMap groups a slice of entities by their Type.
+func NewMap(es []Entity) Map {
+	entityMap := make(Map)
+	for _, e := range es {
+		entityMap[e.Type] = append(entityMap[e.Type], e)
+	}
+	return entityMap
+}
+
 // A Mention is a wrapper for TextSpan objects.
 type Mention struct {
-	TextSpan TextSpan `json:"text"`
+	TextSpan TextSpan    `json:"text"`
+	Type     MentionType `json:"type,omitempty"`
+
+	// Sentiment is the sentiment expressed for the entity by this mention. It
+	// is only set by a sentiment request.
+	Sentiment *sentiment.Sentiment `json:"sentiment,omitempty"`
+}
+
+// End returns the offset just past the end of the mention, in the encoding of
+// the request.
+func (m Mention) End() int {
+	return m.TextSpan.End()
+}
+
+// Text returns the text of doc covered by the mention.
+func (m Mention) Text(doc gcnl.Document) (string, error) {
+	return m.TextSpan.Text(doc.Content())
 }
 
+// A MentionType specifies how an entity is mentioned.
+type MentionType string
+
+const (
+	MentionTypeUnknown MentionType = "TYPE_UNKNOWN"
+	MentionTypeProper              = "PROPER"
+	MentionTypeCommon              = "COMMON"
+)
+
 // A TextSpan specifies the offset in the document where an entity was found.
-type TextSpan struct {
-	Content     string `json:"content"`
-	BeginOffset int    `json:"beginOffset"`
+type TextSpan = gcnl.TextSpan
+
+// An Analyzer finds the entities of documents. It is implemented by *Request,
+// and may be implemented by test doubles in code that depends on it.
+type Analyzer interface {
+	AnalyzeEntities(ctx context.Context, doc gcnl.Document) (Map, error)
+}
+
+// An AnalyzerFunc is a function used as an Analyzer.
+type AnalyzerFunc func(ctx context.Context, doc gcnl.Document) (Map, error)
+
+// AnalyzeEntities calls f(ctx, doc).
+func (f AnalyzerFunc) AnalyzeEntities(ctx context.Context, doc gcnl.Document) (Map, error) {
+	return f(ctx, doc)
+}
+
+var _ Analyzer = (*Request)(nil)
+
+// A Request sends documents to the entities API. It must be created using
+// NewRequest, NewClientRequest or their sentiment variants; the zero value
+// fails with ErrNoClient. Its settings are fixed once created, so a Request is
+// safe for concurrent use and may be reused for any number of documents.
+type Request struct {
+	client *gcnl.Client
+	method string
+	enc    gcnl.Encoding
+
+	// Result of the last FromDocument call, for Document and Language
+	last atomic.Value
+}
+
+// lastResult is the document and language stored by FromDocument.
+type lastResult struct {
+	doc      gcnl.Document
+	language string
 }
 
-// A Request represents the JSON object sent to the entities API.
-type request struct {
+// requestBody is the JSON object sent to the entities API.
+type requestBody struct {
 	Doc gcnl.Document `json:"document"`
 	Enc gcnl.Encoding `json:"encodingType"`
-	key string
+}
+
+// Document satisfies the gcnl.DocumentRequest interface for requestBody.
+func (body *requestBody) Document() gcnl.Document {
+	return body.Doc
 }
 
 // NewRequest returns a Request object with the given API key.
-func NewRequest(key string) *request {
-	return &request{
-		Enc: gcnl.EncodingDefault,
-		key: key,
+func NewRequest(key string) *Request {
+	return NewClientRequest(gcnl.NewClient(key))
+}
+
+// NewClientRequest returns a Request object sent using the given Client.
+func NewClientRequest(c *gcnl.Client) *Request {
+	return &Request{
+		client: c,
+		method: Method,
+		enc:    gcnl.EncodingDefault,
+	}
+}
+
+// NewSentimentRequest returns a Request object with the given API key that
+// also analyzes the sentiment expressed for each entity and mention.
+func NewSentimentRequest(key string) *Request {
+	return NewClientSentimentRequest(gcnl.NewClient(key))
+}
+
+// NewClientSentimentRequest returns a Request object sent using the given
+// Client that also analyzes the sentiment expressed for each entity and
+// mention.
+func NewClientSentimentRequest(c *gcnl.Client) *Request {
+	req := NewClientRequest(c)
+	req.method = SentimentMethod
+	return req
+}
+
+// WithEncoding returns a copy of the request that has the API compute offsets
+// using enc. Use gcnl.ByteOffset to convert the returned offsets into indexes
+// of the document content.
+func (req *Request) WithEncoding(enc gcnl.Encoding) *Request {
+	return &Request{
+		client: req.client,
+		method: req.method,
+		enc:    enc,
 	}
 }
 
-// Document returns the document used in the request.
-func (req *request) Document() gcnl.Document {
-	return req.Doc
+// Encoding returns the encoding used by the API to compute offsets.
+func (req *Request) Encoding() gcnl.Encoding {
+	return req.enc
+}
+
+func (req *Request) loadLast() lastResult {
+	last, _ := req.last.Load().(lastResult)
+	return last
+}
+
+// Document returns the last document analyzed by FromDocument and the other
+// From methods. Analyze, AnalyzeEntities, Batch and FromLargeDocument do not
+// change it.
+//
+// Deprecated: the result is undefined when the request is used concurrently.
+// Keep the document passed to FromDocument, or use Analyze.
+func (req *Request) Document() gcnl.Document {
+	return req.loadLast().doc
+}
+
+// Language returns the language of the last document analyzed by FromDocument
+// and the other From methods, as detected by the API if the document did not
+// specify one.
+//
+// Deprecated: the result is undefined when the request is used concurrently.
+// Use Analyze, whose Result includes the language.
+func (req *Request) Language() string {
+	return req.loadLast().language
 }
 
 // FromURL returns a slice of entities retrieved using a given a URL. It expects
 // the content retrieved from URL to be valid HTML.
-func (req *request) FromURL(url string) (entityMap Map, err error) {
-	doc, err := gcnl.NewHTMLDocument(url)
+func (req *Request) FromURL(url string) (entityMap Map, err error) {
+	return req.FromURLContext(context.Background(), url)
+}
+
+// FromURLContext is like FromURL but uses ctx for both fetching the URL, using
+// the Fetcher of the client, and the API request.
+func (req *Request) FromURLContext(ctx context.Context, url string) (entityMap Map, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+	doc, err := req.client.FetchHTML(ctx, url)
 	if err != nil {
 		return
 	}
-	req.Doc = doc
-	return req.do()
+	return req.FromDocumentContext(ctx, doc)
 }
 
 // FromPlainText returns a slice of entities retrieved using a given plain text.
-func (req *request) FromPlainText(content string) (entityMap Map, err error) {
-	req.Doc = gcnl.NewPlainTextDocument(content)
-	return req.do()
+func (req *Request) FromPlainText(content string) (entityMap Map, err error) {
+	return req.FromPlainTextContext(context.Background(), content)
 }
 
-// Do makes the actual API request for a given Request.
-func (req *request) do() (entityMap Map, err error) {
-	if len(req.key) == 0 {
-		err = ErrMissingKey
-		return
-	}
+// FromPlainTextContext is like FromPlainText but uses ctx for the API request.
+func (req *Request) FromPlainTextContext(ctx context.Context, content string) (entityMap Map, err error) {
+	return req.FromDocumentContext(ctx, gcnl.NewPlainTextDocument(content))
+}
 
-	d, err := json.Marshal(req)
-	if err != nil {
-		return
-	}
-	buf := bytes.NewBuffer(d)
+// FromDocument returns a slice of entities retrieved from the given document.
+func (req *Request) FromDocument(doc gcnl.Document) (entityMap Map, err error) {
+	return req.FromDocumentContext(context.Background(), doc)
+}
 
-	r, err := http.NewRequest("POST", fmt.Sprintf("%s?key=%s", Endpoint, req.key), buf)
-	if err != nil {
-		return
-	}
+// FromDocumentContext is like FromDocument but uses ctx for the API request.
+func (req *Request) FromDocumentContext(ctx context.Context, doc gcnl.Document) (entityMap Map, err error) {
+	var language string
+	entityMap, language, err = req.do(ctx, doc)
+	req.last.Store(lastResult{doc, language})
+	return
+}
 
-	resp, err := http.DefaultClient.Do(r)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
+// AnalyzeEntities satisfies the Analyzer interface for Request. It is like
+// FromDocumentContext but does not change Document and Language.
+func (req *Request) AnalyzeEntities(ctx context.Context, doc gcnl.Document) (Map, error) {
+	entityMap, _, err := req.do(ctx, doc)
+	return entityMap, err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("entities.do: returned %s", resp.Status)
-		return
-	}
+// Analyze is like AnalyzeEntities but returns a Result, which also holds the
+// language of doc as detected by the API.
+func (req *Request) Analyze(ctx context.Context, doc gcnl.Document) Result {
+	res := Result{Document: doc}
+	res.Map, res.Language, res.Err = req.do(ctx, doc)
+	return res
+}
 
+// Do makes the actual API request for a given document.
+func (req *Request) do(ctx context.Context, doc gcnl.Document) (entityMap Map, language string, err error) {
 	var jsonResp struct {
 		Entities []Entity `json:"entities"`
+		Language string   `json:"language"`
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
-	if err != nil {
+	if req.client == nil {
+		err = ErrNoClient
 		return
 	}
 
-	entityMap = make(Map)
+	body := &requestBody{Doc: doc, Enc: req.enc}
+	err = req.client.DoContext(ctx, req.method, body, &jsonResp)
+	if err != nil {
+		return
+	}
 
-	for _, e := range jsonResp.Entities {
-		if entityMap[e.Type] == nil {
-			entityMap[e.Type] = make([]Entity, 0)
+	for i := range jsonResp.Entities {
+		for j := range jsonResp.Entities[i].Mentions {
+			jsonResp.Entities[i].Mentions[j].TextSpan.Encoding = req.enc
 		}
-		entityMap[e.Type] = append(entityMap[e.Type], e)
 	}
 
+	entityMap = NewMap(jsonResp.Entities)
+	language = jsonResp.Language
 	return
 }
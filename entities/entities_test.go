@@ -0,0 +1,93 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+func TestZeroRequest(t *testing.T) {
+	var req entities.Request
+	if _, err := req.FromPlainText("Larry Page founded Google."); err != entities.ErrNoClient {
+		t.Errorf("got error %v, want %v", err, entities.ErrNoClient)
+	}
+	if _, err := req.FromURL("http://example.com/"); err != entities.ErrNoClient {
+		t.Errorf("got error %v, want %v", err, entities.ErrNoClient)
+	}
+}
+
+func TestRequestConcurrentReuse(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	var a entities.Analyzer = entities.NewClientRequest(srv.Client())
+	names := []string{"Larry Page", "Sergey Brin", "Eric Schmidt", "Sundar Pichai"}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			m, err := a.AnalyzeEntities(context.Background(), gcnl.NewPlainTextDocument("I met "+name+" today."))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if es := m.Entities(); len(es) != 1 || es[0].Name != name {
+				t.Errorf("got %+v, want only %q", es, name)
+			}
+		}(name)
+	}
+	wg.Wait()
+}
+
+func TestRequestDocument(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.RespondJSON(entities.Method, map[string]string{"language": "fr"})
+
+	req := entities.NewClientRequest(srv.Client())
+	if req.Document() != nil || req.Language() != "" {
+		t.Fatal("new request has a document")
+	}
+
+	doc := gcnl.NewPlainTextDocument("Bonjour Paris.")
+	if _, err := req.FromDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	if req.Document() != doc || req.Language() != "fr" {
+		t.Errorf("got %v %q, want the analyzed document and fr", req.Document(), req.Language())
+	}
+
+	// Analyze reports the language itself and leaves the request alone
+	srv.RespondJSON(entities.Method, map[string]string{"language": "de"})
+	res := req.Analyze(context.Background(), gcnl.NewPlainTextDocument("Hallo Berlin."))
+	if res.Err != nil || res.Language != "de" {
+		t.Errorf("got %v %q, want de", res.Err, res.Language)
+	}
+	if req.Document() != doc || req.Language() != "fr" {
+		t.Error("Analyze changed the last document of the request")
+	}
+}
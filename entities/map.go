@@ -0,0 +1,202 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities
+
+import (
+	"sort"
+
+	"github.com/jlubawy/go-gcnl/sentiment"
+)
+
+// Types returns the entity types present in the map, sorted alphabetically so
+// that the map can be iterated in a deterministic order.
+func (m Map) Types() []Type {
+	types := make([]Type, 0, len(m))
+	for t := range m {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// Len returns the total number of entities in the map.
+func (m Map) Len() int {
+	n := 0
+	for _, es := range m {
+		n += len(es)
+	}
+	return n
+}
+
+// SortBySalience sorts es by decreasing salience. Entities of equal salience
+// are sorted by name, then type.
+func SortBySalience(es []Entity) {
+	sort.SliceStable(es, func(i, j int) bool {
+		a, b := es[i], es[j]
+		if a.Salience != b.Salience {
+			return a.Salience > b.Salience
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Type < b.Type
+	})
+}
+
+// Entities returns all the entities of the map in a single slice sorted by
+// decreasing salience.
+func (m Map) Entities() []Entity {
+	es := make([]Entity, 0, m.Len())
+	for _, t := range m.Types() {
+		es = append(es, m[t]...)
+	}
+	SortBySalience(es)
+	return es
+}
+
+// Top returns the n most salient entities of the map, or all of them if there
+// are fewer than n.
+func (m Map) Top(n int) []Entity {
+	es := m.Entities()
+	if n >= 0 && n < len(es) {
+		es = es[:n]
+	}
+	return es
+}
+
+// Sort sorts the entities of each type of the map by decreasing salience.
+func (m Map) Sort() {
+	for _, es := range m {
+		SortBySalience(es)
+	}
+}
+
+// Filter returns a new map containing the entities with a salience of at
+// least minSalience and, if any types are given, of one of those types.
+func (m Map) Filter(minSalience float64, types ...Type) Map {
+	allowed := make(map[Type]bool)
+	for _, t := range types {
+		allowed[t] = true
+	}
+
+	filtered := make(Map)
+	for t, es := range m {
+		if len(types) > 0 && !allowed[t] {
+			continue
+		}
+		for _, e := range es {
+			if e.Salience >= minSalience {
+				filtered[t] = append(filtered[t], e)
+			}
+		}
+	}
+	return filtered
+}
+
+// Merge combines the maps of several documents into one. Entities of the same
+// Type are matched the same way as by Link, while entities of different types
+// are never merged. A merged entity takes the name of its first occurrence, the
+// union of the metadata and the mentions of every occurrence. Its salience is
+// summed within each document and its sentiment averaged within each document,
+// then both are averaged over the documents, so that a document mentioning the
+// entity many times does not outweigh the others. Note that the offsets of the
+// mentions are relative to their own document.
+func Merge(maps ...Map) Map {
+	merged := make(Map)
+	for _, g := range linkByType(maps...) {
+		e := mergeGroup(g)
+		merged[e.Type] = append(merged[e.Type], e)
+	}
+	merged.Sort()
+	return merged
+}
+
+// linkByType is like Link but only links entities of the same Type, so that for
+// example a person and a location of the same name are kept apart.
+func linkByType(maps ...Map) []*Group {
+	all := make(Map)
+	for _, m := range maps {
+		for t := range m {
+			all[t] = nil
+		}
+	}
+
+	var groups []*Group
+	for _, t := range all.Types() {
+		typed := make([]Map, len(maps))
+		for i, m := range maps {
+			typed[i] = Map{t: m[t]}
+		}
+		groups = append(groups, Link(typed...)...)
+	}
+	return groups
+}
+
+func mergeGroup(g *Group) Entity {
+	first := g.Occurrences[0].Entity
+	e := Entity{
+		Name:     first.Name,
+		Type:     first.Type,
+		Metadata: make(map[string]string),
+	}
+
+	salience := make(map[int]float64)
+	sentiments := make(map[int][]sentiment.Sentiment)
+
+	for _, o := range g.Occurrences {
+		for k, v := range o.Entity.Metadata {
+			if _, ok := e.Metadata[k]; !ok {
+				e.Metadata[k] = v
+			}
+		}
+		e.Mentions = append(e.Mentions, o.Entity.Mentions...)
+		salience[o.Document] += o.Entity.Salience
+		if o.Entity.Sentiment != nil {
+			sentiments[o.Document] = append(sentiments[o.Document], *o.Entity.Sentiment)
+		}
+	}
+
+	for _, s := range salience {
+		e.Salience += s
+	}
+	e.Salience /= float64(len(salience))
+
+	if len(sentiments) > 0 {
+		perDocument := make([]sentiment.Sentiment, 0, len(sentiments))
+		for _, ss := range sentiments {
+			perDocument = append(perDocument, averageSentiment(ss))
+		}
+		s := averageSentiment(perDocument)
+		e.Sentiment = &s
+	}
+
+	return e
+}
+
+func averageSentiment(ss []sentiment.Sentiment) (avg sentiment.Sentiment) {
+	for _, s := range ss {
+		avg.Polarity += s.Polarity
+		avg.Magnitude += s.Magnitude
+		avg.Score += s.Score
+	}
+	n := float64(len(ss))
+	avg.Polarity /= n
+	avg.Magnitude /= n
+	avg.Score /= n
+	return
+}
@@ -0,0 +1,119 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl/sentiment"
+)
+
+func TestMergeKeepsTypesApart(t *testing.T) {
+	m := Merge(Map{
+		TypePerson:   {{Name: "Washington", Type: TypePerson, Salience: 0.6}},
+		TypeLocation: {{Name: "Washington", Type: TypeLocation, Salience: 0.4}},
+	})
+	if n := m.Len(); n != 2 {
+		t.Fatalf("got %d entities, want 2", n)
+	}
+	if s := m[TypePerson][0].Salience; s != 0.6 {
+		t.Errorf("person salience = %v, want 0.6", s)
+	}
+	if s := m[TypeLocation][0].Salience; s != 0.4 {
+		t.Errorf("location salience = %v, want 0.4", s)
+	}
+}
+
+func TestMergeAcrossDocuments(t *testing.T) {
+	google := func(salience float64) Entity {
+		return Entity{
+			Name:     "Google",
+			Type:     TypeOrganization,
+			Metadata: map[string]string{MetadataMID: "/m/045c7b"},
+			Salience: salience,
+			Mentions: []Mention{{TextSpan: TextSpan{Content: "Google"}}},
+		}
+	}
+	m := Merge(
+		Map{TypeOrganization: {google(0.8)}},
+		Map{TypeOrganization: {google(0.4)}},
+		Map{TypeOrganization: {{Name: "google", Type: TypeOrganization, Salience: 0.3}}},
+	)
+	if n := m.Len(); n != 1 {
+		t.Fatalf("got %d entities, want 1", n)
+	}
+	e := m[TypeOrganization][0]
+	if e.Name != "Google" || e.MID() != "/m/045c7b" {
+		t.Errorf("got %q %q", e.Name, e.MID())
+	}
+	if want := (0.8 + 0.4 + 0.3) / 3; math.Abs(e.Salience-want) > 1e-9 {
+		t.Errorf("salience = %v, want %v", e.Salience, want)
+	}
+	if len(e.Mentions) != 2 {
+		t.Errorf("got %d mentions, want 2", len(e.Mentions))
+	}
+}
+
+func TestMergeSentimentPerDocument(t *testing.T) {
+	google := func(name string, score, magnitude float64) Entity {
+		e := Entity{
+			Name:     name,
+			Type:     TypeOrganization,
+			Metadata: map[string]string{MetadataMID: "/m/045c7b"},
+			Salience: 0.5,
+		}
+		if magnitude > 0 {
+			e.Sentiment = &sentiment.Sentiment{Polarity: score, Score: score, Magnitude: magnitude}
+		}
+		return e
+	}
+
+	// The first document mentions Google twice, which must not weigh more than
+	// the single mention of the second document. The third document has no
+	// sentiment.
+	m := Merge(
+		Map{TypeOrganization: {google("Google", 1, 3), google("Google Inc.", 0.6, 1)}},
+		Map{TypeOrganization: {google("Google", -0.4, 1)}},
+		Map{TypeOrganization: {google("Google", 0, 0)}},
+	)
+	if n := m.Len(); n != 1 {
+		t.Fatalf("got %d entities, want 1", n)
+	}
+	e := m[TypeOrganization][0]
+	if e.Sentiment == nil {
+		t.Fatal("got no sentiment")
+	}
+
+	want := sentiment.Sentiment{Polarity: 0.2, Score: 0.2, Magnitude: 1.5}
+	for _, f := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"polarity", e.Sentiment.Polarity, want.Polarity},
+		{"score", e.Sentiment.Score, want.Score},
+		{"magnitude", e.Sentiment.Magnitude, want.Magnitude},
+	} {
+		if math.Abs(f.got-f.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+		}
+	}
+	if want := (1.0 + 0.5 + 0.5) / 3; math.Abs(e.Salience-want) > 1e-9 {
+		t.Errorf("salience = %v, want %v", e.Salience, want)
+	}
+}
@@ -0,0 +1,167 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package entities
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Metadata keys set by the API.
+const (
+	MetadataWikipediaURL = "wikipedia_url"
+	MetadataMID          = "mid"
+)
+
+// WikipediaURL returns the URL of the Wikipedia article about the entity, or
+// an empty string if there is none.
+func (e Entity) WikipediaURL() string {
+	return e.Metadata[MetadataWikipediaURL]
+}
+
+// MID returns the Google Knowledge Graph machine ID of the entity, for example
+// "/m/0k8z", or an empty string if there is none.
+func (e Entity) MID() string {
+	return e.Metadata[MetadataMID]
+}
+
+// NormalizeName returns name in lower case with surrounding punctuation
+// removed and whitespace collapsed, so that variations of the same name
+// compare equal.
+func NormalizeName(name string) string {
+	name = strings.TrimFunc(name, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Key returns a string identifying the entity across documents: its Knowledge
+// Graph MID if it has one, and its normalized name otherwise.
+func (e Entity) Key() string {
+	if mid := e.MID(); len(mid) > 0 {
+		return "mid:" + mid
+	}
+	return "name:" + NormalizeName(e.Name)
+}
+
+// An Occurrence is an entity found in one of the documents given to Link.
+type Occurrence struct {
+	Document int // index of the document's Map
+	Entity   Entity
+}
+
+// A Group is a set of entities of several documents referring to the same
+// thing.
+type Group struct {
+	Key          string
+	MID          string
+	Name         string // name of the first occurrence
+	WikipediaURL string
+	Occurrences  []Occurrence
+}
+
+// Documents returns the number of distinct documents the group occurs in.
+func (g *Group) Documents() int {
+	docs := make(map[int]bool)
+	for _, o := range g.Occurrences {
+		docs[o.Document] = true
+	}
+	return len(docs)
+}
+
+// Link groups the entities of several documents by Knowledge Graph MID.
+// Entities without a MID join the group of an entity with a MID and the same
+// normalized name, if any, or are grouped by normalized name. Groups are
+// sorted by decreasing number of documents, then by Key.
+func Link(maps ...Map) []*Group {
+	var occurrences []Occurrence
+	for i, m := range maps {
+		for _, es := range m {
+			for _, e := range es {
+				occurrences = append(occurrences, Occurrence{i, e})
+			}
+		}
+	}
+
+	// Keep the order of occurrences independent of map iteration
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		a, b := occurrences[i], occurrences[j]
+		if a.Document != b.Document {
+			return a.Document < b.Document
+		}
+		if a.Entity.Name != b.Entity.Name {
+			return a.Entity.Name < b.Entity.Name
+		}
+		return a.Entity.Type < b.Entity.Type
+	})
+
+	groups := make(map[string]*Group)
+	midByName := make(map[string]string)
+
+	add := func(key string, o Occurrence) {
+		g, ok := groups[key]
+		if !ok {
+			g = &Group{Key: key, Name: o.Entity.Name}
+			groups[key] = g
+		}
+		if len(g.MID) == 0 {
+			g.MID = o.Entity.MID()
+		}
+		if len(g.WikipediaURL) == 0 {
+			g.WikipediaURL = o.Entity.WikipediaURL()
+		}
+		g.Occurrences = append(g.Occurrences, o)
+	}
+
+	for _, o := range occurrences {
+		if mid := o.Entity.MID(); len(mid) > 0 {
+			add(o.Entity.Key(), o)
+			name := NormalizeName(o.Entity.Name)
+			if _, ok := midByName[name]; !ok {
+				midByName[name] = o.Entity.Key()
+			}
+		}
+	}
+	for _, o := range occurrences {
+		if len(o.Entity.MID()) == 0 {
+			if key, ok := midByName[NormalizeName(o.Entity.Name)]; ok {
+				add(key, o)
+			} else {
+				add(o.Entity.Key(), o)
+			}
+		}
+	}
+
+	linked := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		sort.SliceStable(g.Occurrences, func(i, j int) bool {
+			return g.Occurrences[i].Document < g.Occurrences[j].Document
+		})
+		linked = append(linked, g)
+	}
+	sort.Slice(linked, func(i, j int) bool {
+		di, dj := linked[i].Documents(), linked[j].Documents()
+		if di != dj {
+			return di > dj
+		}
+		return linked[i].Key < linked[j].Key
+	})
+
+	return linked
+}
@@ -0,0 +1,164 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Canonical error statuses returned by the API.
+const (
+	StatusInvalidArgument   = "INVALID_ARGUMENT"
+	StatusUnauthenticated   = "UNAUTHENTICATED"
+	StatusPermissionDenied  = "PERMISSION_DENIED"
+	StatusResourceExhausted = "RESOURCE_EXHAUSTED"
+	StatusUnavailable       = "UNAVAILABLE"
+)
+
+// An APIError is returned when the API responds with a status other than
+// 200 OK. Its fields are decoded from the Google error envelope when present.
+type APIError struct {
+	// Method is the API method that failed, for example "analyzeEntities".
+	Method string `json:"-"`
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
+
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Details []json.RawMessage `json:"details"`
+
+	// RetryAfter is the delay requested by the Retry-After response header,
+	// or zero if absent.
+	RetryAfter time.Duration `json:"-"`
+
+	// Body is the raw response body.
+	Body []byte `json:"-"`
+}
+
+// newAPIError builds an APIError from a failed response.
+func newAPIError(method string, resp *http.Response) *APIError {
+	e := &APIError{
+		Method:     method,
+		StatusCode: resp.StatusCode,
+	}
+
+	e.Body, _ = ioutil.ReadAll(resp.Body)
+	e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	var envelope struct {
+		Error *APIError `json:"error"`
+	}
+	envelope.Error = e
+	if err := json.Unmarshal(e.Body, &envelope); err != nil || e.Code == 0 {
+		e.Code = resp.StatusCode
+	}
+	if len(e.Message) == 0 {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return e
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if len(v) == 0 {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func (e *APIError) Error() string {
+	status := e.Status
+	if len(status) == 0 {
+		status = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("gcnl: %s returned %d %s: %s", e.Method, e.StatusCode, status, e.Message)
+}
+
+// IsTemporary reports whether the request failed because of a transient
+// condition and may succeed if sent again unchanged.
+func (e *APIError) IsTemporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsQuotaExceeded reports whether the request was rejected because a quota or
+// rate limit was exceeded.
+func (e *APIError) IsQuotaExceeded() bool {
+	return e.Status == StatusResourceExhausted || e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsInvalidArgument reports whether the request was rejected because it was
+// malformed, for example because the document was empty or too large.
+func (e *APIError) IsInvalidArgument() bool {
+	return e.Status == StatusInvalidArgument || (len(e.Status) == 0 && e.StatusCode == http.StatusBadRequest)
+}
+
+// IsUnauthenticated reports whether the request was rejected because of
+// missing or invalid credentials.
+func (e *APIError) IsUnauthenticated() bool {
+	return e.Status == StatusUnauthenticated || e.StatusCode == http.StatusUnauthorized
+}
+
+// IsQuotaExceeded reports whether err is an *APIError for which the method of
+// the same name returns true.
+func IsQuotaExceeded(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.IsQuotaExceeded()
+}
+
+// IsInvalidArgument reports whether err is an *APIError for which the method
+// of the same name returns true.
+func IsInvalidArgument(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.IsInvalidArgument()
+}
+
+// IsUnauthenticated reports whether err is an *APIError for which the method
+// of the same name returns true.
+func IsUnauthenticated(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.IsUnauthenticated()
+}
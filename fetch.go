@@ -0,0 +1,237 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent sent by a Fetcher unless set in its
+// Header.
+const DefaultUserAgent = "go-gcnl (+https://github.com/jlubawy/go-gcnl)"
+
+var (
+	ErrContentType = errors.New("gcnl: content type not allowed")
+	ErrTooManyHops = errors.New("gcnl: stopped after too many redirects")
+)
+
+// A Fetcher retrieves HTML documents over HTTP. The zero value is ready to
+// use. A Fetcher is safe for concurrent use once configured.
+type Fetcher struct {
+	// Client is used to send requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Header contains additional headers sent with every request, such as
+	// Accept-Language or User-Agent.
+	Header http.Header
+
+	// Timeout limits the time taken by a single fetch, including reading
+	// the body. If zero, there is no timeout other than the one of Client.
+	Timeout time.Duration
+
+	// MaxBodyBytes is the maximum size of the response body. If zero,
+	// DefaultMaxSize is used. If negative, the size is not limited.
+	MaxBodyBytes int64
+
+	// MaxRedirects is the maximum number of redirects followed. If zero, the
+	// policy of Client is used. If negative, redirects are not followed and
+	// fail with a FetchError reporting the redirect status.
+	MaxRedirects int
+
+	// AllowedContentTypes lists the media types accepted, for example
+	// "text/html". If empty, any media type is accepted.
+	AllowedContentTypes []string
+
+	// CharsetReader converts charsets other than UTF-8, UTF-16, US-ASCII,
+	// ISO-8859-1 and Windows-1252 to UTF-8. If nil, documents in other
+	// charsets fail with ErrUnsupportedCharset.
+	CharsetReader CharsetReader
+}
+
+// DefaultFetcher is the Fetcher used by NewHTMLDocument.
+var DefaultFetcher = &Fetcher{
+	Timeout: 30 * time.Second,
+}
+
+// A FetchError is returned by a Fetcher when a document cannot be retrieved.
+type FetchError struct {
+	URL        string // final URL, after following redirects
+	StatusCode int    // HTTP status code, or zero if no response was received
+	Status     string // HTTP status, or empty if no response was received
+	Err        error  // underlying error, or nil if the status was not 200 OK
+}
+
+func (e *FetchError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("gcnl: fetching %s returned %s", e.URL, e.Status)
+	}
+	if len(e.Status) == 0 {
+		return fmt.Sprintf("gcnl: fetching %s: %v", e.URL, e.Err)
+	}
+	return fmt.Sprintf("gcnl: fetching %s (%s): %v", e.URL, e.Status, e.Err)
+}
+
+func (e *FetchError) Unwrap() error { return e.Err }
+
+func (f *Fetcher) client() *http.Client {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if f.MaxRedirects == 0 {
+		return client
+	}
+
+	c := *client
+	c.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if f.MaxRedirects < 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > f.MaxRedirects {
+			return ErrTooManyHops
+		}
+		return nil
+	}
+	return &c
+}
+
+// unwrapURLError returns the URL and the underlying error of err, returned by
+// http.Client.Do. The URL is that of the last request sent, after following
+// redirects. If err is not a *url.Error, rawURL and err are returned.
+func unwrapURLError(rawURL string, err error) (string, error) {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.URL, urlErr.Err
+	}
+	return rawURL, err
+}
+
+func (f *Fetcher) allowed(contentType string) bool {
+	if len(f.AllowedContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range f.AllowedContentTypes {
+		if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch retrieves the HTML document at url and converts it to UTF-8 based on
+// the charset declared in the Content-Type header or in a meta tag. If ctx is
+// done before the document is retrieved ctx.Err() is returned, any other error
+// is a *FetchError.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (doc Document, err error) {
+	fetchCtx := ctx
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	r, err := http.NewRequestWithContext(fetchCtx, "GET", url, nil)
+	if err != nil {
+		err = &FetchError{URL: url, Err: err}
+		return
+	}
+	for k, vs := range f.Header {
+		r.Header[k] = append([]string(nil), vs...)
+	}
+	if len(r.Header.Get("User-Agent")) == 0 {
+		r.Header.Set("User-Agent", DefaultUserAgent)
+	}
+
+	resp, err := f.client().Do(r)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+		finalURL, urlErr := unwrapURLError(url, err)
+		err = &FetchError{URL: finalURL, Err: urlErr}
+		return
+	}
+	defer resp.Body.Close()
+
+	fetchErr := &FetchError{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err = fetchErr
+		return
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !f.allowed(contentType) {
+		fetchErr.Err = fmt.Errorf("%w: %q", ErrContentType, contentType)
+		err = fetchErr
+		return
+	}
+
+	max := f.MaxBodyBytes
+	if max == 0 {
+		max = DefaultMaxSize
+	}
+	var body io.Reader = resp.Body
+	if max > 0 {
+		body = io.LimitReader(body, max+1)
+	}
+
+	d, err := ioutil.ReadAll(body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+		fetchErr.Err = err
+		err = fetchErr
+		return
+	}
+	if max > 0 && int64(len(d)) > max {
+		fetchErr.Err = ErrDocumentTooLarge
+		err = fetchErr
+		return
+	}
+
+	content, err := decodeCharset(htmlCharset(contentType, d), d, f.CharsetReader)
+	if err != nil {
+		fetchErr.Err = err
+		err = fetchErr
+		return
+	}
+
+	doc = &HTMLDocument{content, LanguageEnglish}
+	return
+}
@@ -0,0 +1,170 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFetchRedirects(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hop, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if hop < 3 {
+			http.Redirect(w, r, fmt.Sprintf("%s/%d", srv.URL, hop+1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("done"))
+	}))
+	defer srv.Close()
+
+	f := &Fetcher{MaxRedirects: 1, AllowedContentTypes: []string{"text/html"}}
+	_, err := f.Fetch(context.Background(), srv.URL+"/0")
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || !errors.Is(err, ErrTooManyHops) {
+		t.Fatalf("got error %v, want ErrTooManyHops", err)
+	}
+	if want := srv.URL + "/2"; fetchErr.URL != want {
+		t.Errorf("got URL %s, want %s", fetchErr.URL, want)
+	}
+
+	f.MaxRedirects = 5
+	_, err = f.Fetch(context.Background(), srv.URL+"/0")
+	if !errors.As(err, &fetchErr) || !errors.Is(err, ErrContentType) {
+		t.Fatalf("got error %v, want ErrContentType", err)
+	}
+	if want := srv.URL + "/3"; fetchErr.URL != want {
+		t.Errorf("got URL %s, want %s", fetchErr.URL, want)
+	}
+}
+
+func TestFetchCharset(t *testing.T) {
+	errCharset := errors.New("charset reader failed")
+	upper := func(charset string, input io.Reader) (io.Reader, error) {
+		if charset != "shift_jis" {
+			return nil, errCharset
+		}
+		d, _ := ioutil.ReadAll(input)
+		return strings.NewReader(strings.ToUpper(string(d))), nil
+	}
+
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		maxBodyBytes  int64
+		charsetReader CharsetReader
+		want          string
+		wantErr       error
+	}{
+		{name: "no charset", contentType: "text/html", body: "café", want: "café"},
+		{name: "invalid UTF-8", contentType: "text/html", body: "a\xffb", want: "a�b"},
+		{name: "header UTF-8", contentType: "text/html; charset=UTF-8", body: "café", want: "café"},
+		{name: "header Windows-1252", contentType: "text/html; charset=windows-1252", body: "caf\xe9 \x80", want: "café €"},
+		{name: "header ISO-8859-1", contentType: "text/html; charset=iso-8859-1", body: "\x93hi\x94", want: "“hi”"},
+		{name: "header UTF-16LE", contentType: "text/html; charset=utf-16le", body: "h\x00i\x00", want: "hi"},
+		{name: "header UTF-16BE", contentType: "text/html; charset=utf-16be", body: "\x00h\x00i", want: "hi"},
+		{
+			name:        "meta Windows-1252",
+			contentType: "text/html",
+			body:        `<meta charset="windows-1252"><p>caf` + "\xe9",
+			want:        `<meta charset="windows-1252"><p>café`,
+		},
+		{
+			name:        "meta http-equiv",
+			contentType: "text/html",
+			body:        `<meta http-equiv="Content-Type" content="text/html; charset=cp1252">` + "\xe9",
+			want:        `<meta http-equiv="Content-Type" content="text/html; charset=cp1252">é`,
+		},
+		{
+			name:        "meta UTF-16 means UTF-8",
+			contentType: "text/html",
+			body:        `<meta charset="UTF-16"><p>café`,
+			want:        `<meta charset="UTF-16"><p>café`,
+		},
+		{
+			name:        "meta after 1024 bytes",
+			contentType: "text/html",
+			body:        strings.Repeat(" ", 1024) + `<meta charset="windows-1252">`,
+			want:        strings.Repeat(" ", 1024) + `<meta charset="windows-1252">`,
+		},
+		{
+			name:        "header before meta",
+			contentType: "text/html; charset=utf-8",
+			body:        `<meta charset="windows-1252">café`,
+			want:        `<meta charset="windows-1252">café`,
+		},
+		{name: "UTF-8 BOM", contentType: "text/html; charset=windows-1252", body: "\xef\xbb\xbfcafé", want: "café"},
+		{name: "UTF-16LE BOM", contentType: "text/html; charset=utf-8", body: "\xff\xfeh\x00i\x00", want: "hi"},
+		{name: "UTF-16BE BOM", contentType: "text/html", body: "\xfe\xff\x00h\x00i", want: "hi"},
+		{name: "at max size", contentType: "text/html", body: "1234", maxBodyBytes: 4, want: "1234"},
+		{name: "over max size", contentType: "text/html", body: "12345", maxBodyBytes: 4, wantErr: ErrDocumentTooLarge},
+		{name: "unlimited size", contentType: "text/html", body: "12345", maxBodyBytes: -1, want: "12345"},
+		{name: "unsupported", contentType: "text/html; charset=shift_jis", body: "abc", wantErr: ErrUnsupportedCharset},
+		{
+			name:          "charset reader",
+			contentType:   "text/html; charset=Shift_JIS",
+			body:          "abc",
+			charsetReader: upper,
+			want:          "ABC",
+		},
+		{
+			name:          "charset reader error",
+			contentType:   "text/html; charset=koi8-r",
+			body:          "abc",
+			charsetReader: upper,
+			wantErr:       errCharset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			f := &Fetcher{MaxBodyBytes: tt.maxBodyBytes, CharsetReader: tt.charsetReader}
+			doc, err := f.Fetch(context.Background(), srv.URL)
+			if tt.wantErr != nil {
+				var fetchErr *FetchError
+				if !errors.As(err, &fetchErr) || !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Content(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
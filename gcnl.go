@@ -18,10 +18,8 @@
 package gcnl
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 )
 
 // Default to English
@@ -42,29 +40,38 @@ const (
 	TypeHTML             = "HTML"
 )
 
-// MarshalJSON serializes a Document into JSON.
+// MarshalJSON serializes a Document into JSON. Documents with a Google Cloud
+// Storage URI, such as GCSDocument, are serialized using gcsContentUri instead
+// of content.
 func MarshalJSON(doc Document) ([]byte, error) {
 	s := struct {
-		Type     Type   `json:"type"`
-		Language string `json:"language"`
-		Content  string `json:"content"`
+		Type          Type    `json:"type"`
+		Language      string  `json:"language,omitempty"`
+		Content       *string `json:"content,omitempty"`
+		GCSContentURI string  `json:"gcsContentUri,omitempty"`
 	}{
-		doc.Type(),
-		doc.Language(),
-		doc.Content(),
+		Type:     doc.Type(),
+		Language: doc.Language(),
+	}
+
+	if gcs, ok := doc.(gcsDocument); ok && len(gcs.GCSContentURI()) > 0 {
+		s.GCSContentURI = gcs.GCSContentURI()
+	} else {
+		content := doc.Content()
+		s.Content = &content
 	}
 
 	return json.Marshal(&s)
 }
 
-type PlainTextDocument struct{ content string }
+type PlainTextDocument struct{ content, language string }
 
 func (doc *PlainTextDocument) Type() Type       { return TypePlainText }
-func (doc *PlainTextDocument) Language() string { return LanguageEnglish }
+func (doc *PlainTextDocument) Language() string { return doc.language }
 func (doc *PlainTextDocument) Content() string  { return doc.content }
 
 func NewPlainTextDocument(content string) Document {
-	return &PlainTextDocument{content}
+	return &PlainTextDocument{content, LanguageEnglish}
 }
 
 // MarshalJSON satisfies the json.Marshaler interface for PlainTextDocument.
@@ -72,31 +79,21 @@ func (doc *PlainTextDocument) MarshalJSON() ([]byte, error) {
 	return MarshalJSON(doc)
 }
 
-type HTMLDocument struct{ content string }
+type HTMLDocument struct{ content, language string }
 
 func (doc *HTMLDocument) Type() Type       { return TypeHTML }
-func (doc *HTMLDocument) Language() string { return LanguageEnglish }
+func (doc *HTMLDocument) Language() string { return doc.language }
 func (doc *HTMLDocument) Content() string  { return doc.content }
 
+// NewHTMLDocument retrieves the HTML document at url using DefaultFetcher.
 func NewHTMLDocument(url string) (doc Document, err error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("NewHTMLDocument: returned %s", resp.Status)
-		return
-	}
-
-	d, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
+	return NewHTMLDocumentContext(context.Background(), url)
+}
 
-	doc = &HTMLDocument{string(d)}
-	return
+// NewHTMLDocumentContext is like NewHTMLDocument but fetches the URL using ctx.
+// If ctx is done before the document is retrieved ctx.Err() is returned.
+func NewHTMLDocumentContext(ctx context.Context, url string) (doc Document, err error) {
+	return DefaultFetcher.Fetch(ctx, url)
 }
 
 // MarshalJSON satisfies the json.Marshaler interface for HTMLDocument.
@@ -104,6 +101,15 @@ func (doc *HTMLDocument) MarshalJSON() ([]byte, error) {
 	return MarshalJSON(doc)
 }
 
+// A TextSpan specifies a piece of text and its offset within a document.
+// BeginOffset is computed by the API using Encoding, which is set by the
+// method packages from their request. An empty Encoding means EncodingUTF8.
+type TextSpan struct {
+	Content     string   `json:"content"`
+	BeginOffset int      `json:"beginOffset"`
+	Encoding    Encoding `json:"-"`
+}
+
 type Encoding string
 
 const (
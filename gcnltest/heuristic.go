@@ -0,0 +1,395 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnltest
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/annotate"
+	"github.com/jlubawy/go-gcnl/classify"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/sentiment"
+	"github.com/jlubawy/go-gcnl/syntax"
+)
+
+var (
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+	sentenceEnd = regexp.MustCompile(`[.!?]+(\s+|$)|\n\s*\n`)
+	tokenRe     = regexp.MustCompile(`[\pL\pN]+(['’\-][\pL\pN]+)*|[^\s\pL\pN]`)
+)
+
+// Small closed word lists used to tag tokens and to skip capitalized words
+// that start sentences without being names.
+var (
+	determiners  = wordSet("a an the this that these those")
+	pronouns     = wordSet("i you he she it we they me him her us them my your his its our their")
+	adpositions  = wordSet("in on at of to for with from by about into over under after before")
+	conjunctions = wordSet("and or but nor so yet")
+	positive     = wordSet("good great excellent happy love loved like liked best wonderful amazing awesome nice")
+	negative     = wordSet("bad terrible awful hate hated sad worst horrible poor angry disappointing")
+)
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// analysis is the heuristic analysis of a document. Offsets are byte offsets
+// of the document content.
+type analysis struct {
+	content   string
+	enc       gcnl.Encoding
+	sentences [][2]int
+	tokens    [][2]int
+	tokenSent []int // sentence of each token
+}
+
+// newAnalysis splits content into sentences and tokens. Tags of HTML documents
+// are blanked out so that offsets still refer to the original content.
+func newAnalysis(doc Document, enc gcnl.Encoding) *analysis {
+	a := &analysis{content: doc.Content, enc: enc}
+
+	text := doc.Content
+	if doc.Type == gcnl.TypeHTML {
+		text = htmlTag.ReplaceAllStringFunc(text, func(tag string) string {
+			return strings.Repeat(" ", len(tag))
+		})
+	}
+
+	begin := 0
+	bounds := sentenceEnd.FindAllStringIndex(text, -1)
+	bounds = append(bounds, []int{len(text), len(text)})
+	for _, b := range bounds {
+		// Keep the terminating punctuation but not the trailing space
+		end := b[0] + len(strings.TrimRightFunc(text[b[0]:b[1]], unicode.IsSpace))
+		s := text[begin:end]
+		trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+		if start := begin + len(s) - len(trimmed); len(strings.TrimSpace(trimmed)) > 0 {
+			a.sentences = append(a.sentences, [2]int{start, end})
+		}
+		begin = b[1]
+	}
+
+	for i, s := range a.sentences {
+		for _, t := range tokenRe.FindAllStringIndex(text[s[0]:s[1]], -1) {
+			a.tokens = append(a.tokens, [2]int{s[0] + t[0], s[0] + t[1]})
+			a.tokenSent = append(a.tokenSent, i)
+		}
+	}
+
+	return a
+}
+
+func (a *analysis) span(r [2]int) gcnl.TextSpan {
+	offset, err := gcnl.EncodedOffset(a.content, r[0], a.enc)
+	if err != nil {
+		offset = -1
+	}
+	return gcnl.TextSpan{Content: a.content[r[0]:r[1]], BeginOffset: offset}
+}
+
+func (a *analysis) text(r [2]int) string {
+	return a.content[r[0]:r[1]]
+}
+
+func capitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// sentimentOf scores the tokens in [from, to) using the word lists.
+func (a *analysis) sentimentOf(from, to int) sentiment.Sentiment {
+	var pos, neg float64
+	for _, t := range a.tokens[from:to] {
+		w := strings.ToLower(a.text(t))
+		if positive[w] {
+			pos++
+		} else if negative[w] {
+			neg++
+		}
+	}
+
+	var s sentiment.Sentiment
+	if pos+neg > 0 {
+		s.Score = (pos - neg) / (pos + neg)
+		s.Polarity = s.Score
+		s.Magnitude = pos + neg
+	}
+	return s
+}
+
+// sentenceTokens returns the range of tokens of sentence i.
+func (a *analysis) sentenceTokens(i int) (from, to int) {
+	from = -1
+	for j, s := range a.tokenSent {
+		if s == i {
+			if from < 0 {
+				from = j
+			}
+			to = j + 1
+		}
+	}
+	if from < 0 {
+		from = 0
+	}
+	return
+}
+
+func (a *analysis) sentenceList() []sentiment.Sentence {
+	sentences := make([]sentiment.Sentence, 0, len(a.sentences))
+	for i, s := range a.sentences {
+		from, to := a.sentenceTokens(i)
+		sentences = append(sentences, sentiment.Sentence{
+			Text:      a.span(s),
+			Sentiment: a.sentimentOf(from, to),
+		})
+	}
+	return sentences
+}
+
+func (a *analysis) syntaxSentenceList() []syntax.Sentence {
+	sentences := make([]syntax.Sentence, 0, len(a.sentences))
+	for _, s := range a.sentences {
+		sentences = append(sentences, syntax.Sentence{Text: a.span(s)})
+	}
+	return sentences
+}
+
+func (a *analysis) documentSentiment() *sentiment.Sentiment {
+	s := a.sentimentOf(0, len(a.tokens))
+	return &s
+}
+
+func (a *analysis) tokenList() syntax.Tokens {
+	tokens := make(syntax.Tokens, len(a.tokens))
+	root := make(map[int]int) // sentence to root token
+
+	for j, t := range a.tokens {
+		w := a.text(t)
+		lw := strings.ToLower(w)
+
+		pos := syntax.PartOfSpeech{Tag: syntax.TagX}
+		r, _ := utf8.DecodeRuneInString(w)
+		switch {
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			pos.Tag = syntax.TagPunct
+		case unicode.IsDigit(r):
+			pos.Tag = syntax.TagNum
+		case determiners[lw]:
+			pos.Tag = syntax.TagDet
+		case pronouns[lw]:
+			pos.Tag = syntax.TagPron
+		case adpositions[lw]:
+			pos.Tag = syntax.TagAdp
+		case conjunctions[lw]:
+			pos.Tag = syntax.TagConj
+		case capitalized(w):
+			pos.Tag = syntax.TagNoun
+			pos.Proper = syntax.ProperProper
+		}
+
+		if _, ok := root[a.tokenSent[j]]; !ok && pos.Tag != syntax.TagPunct {
+			root[a.tokenSent[j]] = j
+		}
+
+		tokens[j] = syntax.Token{
+			Text:         a.span(t),
+			PartOfSpeech: pos,
+			Lemma:        lw,
+		}
+	}
+
+	// Every token of a sentence depends on its first word
+	for j := range tokens {
+		head, ok := root[a.tokenSent[j]]
+		if !ok {
+			head = j
+		}
+		label := syntax.Label(syntax.LabelDep)
+		switch {
+		case head == j:
+			label = syntax.LabelRoot
+		case tokens[j].PartOfSpeech.Tag == syntax.TagPunct:
+			label = syntax.LabelP
+		}
+		tokens[j].DependencyEdge = syntax.DependencyEdge{HeadTokenIndex: head, Label: label}
+	}
+
+	return tokens
+}
+
+// closedClass reports whether word is a determiner, pronoun, adposition or
+// conjunction, which are never part of names.
+func closedClass(word string) bool {
+	w := strings.ToLower(word)
+	return determiners[w] || pronouns[w] || adpositions[w] || conjunctions[w]
+}
+
+// entityList returns runs of capitalized words within a sentence as entities
+// of type OTHER, ignoring closed-class words. Salience is the share of all
+// mentions.
+func (a *analysis) entityList(withSentiment bool) []entities.Entity {
+	var list []entities.Entity
+	index := make(map[string]int)
+	total := 0
+
+	addMention := func(from, to int) {
+		r := [2]int{a.tokens[from][0], a.tokens[to-1][1]}
+		name := a.text(r)
+		i, ok := index[name]
+		if !ok {
+			i = len(list)
+			index[name] = i
+			list = append(list, entities.Entity{
+				Name:     name,
+				Type:     entities.TypeOther,
+				Metadata: map[string]string{},
+			})
+		}
+
+		m := entities.Mention{TextSpan: a.span(r), Type: entities.MentionTypeProper}
+		if withSentiment {
+			sf, st := a.sentenceTokens(a.tokenSent[from])
+			s := a.sentimentOf(sf, st)
+			m.Sentiment = &s
+		}
+		list[i].Mentions = append(list[i].Mentions, m)
+		total++
+	}
+
+	start := -1
+	for j, t := range a.tokens {
+		w := a.text(t)
+		name := capitalized(w) && !closedClass(w)
+		contiguous := start >= 0 && a.tokenSent[j-1] == a.tokenSent[j] &&
+			len(strings.TrimSpace(a.content[a.tokens[j-1][1]:t[0]])) == 0
+
+		if name && (start < 0 || contiguous) {
+			if start < 0 {
+				start = j
+			}
+			continue
+		}
+		if start >= 0 {
+			addMention(start, j)
+			start = -1
+		}
+		if name {
+			start = j
+		}
+	}
+	if start >= 0 {
+		addMention(start, len(a.tokens))
+	}
+
+	for i := range list {
+		list[i].Salience = float64(len(list[i].Mentions)) / float64(total)
+		if withSentiment {
+			var s sentiment.Sentiment
+			for _, m := range list[i].Mentions {
+				s.Score += m.Sentiment.Score
+				s.Polarity += m.Sentiment.Polarity
+				s.Magnitude += m.Sentiment.Magnitude
+			}
+			n := float64(len(list[i].Mentions))
+			s.Score /= n
+			s.Polarity /= n
+			s.Magnitude /= n
+			list[i].Sentiment = &s
+		}
+	}
+
+	return list
+}
+
+// analyze returns the heuristic response of method for the request.
+func analyze(method string, req *requestBody) response {
+	doc := req.Document
+	if len(doc.GCSContentURI) > 0 {
+		return errorResponse(http.StatusBadRequest, "gcnltest: gcsContentUri documents are not supported")
+	}
+	if len(strings.TrimSpace(doc.Content)) == 0 {
+		return errorResponse(http.StatusBadRequest, "The document is empty.")
+	}
+
+	enc := req.EncodingType
+	if len(enc) == 0 {
+		enc = gcnl.EncodingNone
+	}
+	a := newAnalysis(doc, enc)
+
+	language := doc.Language
+	if len(language) == 0 {
+		language = gcnl.LanguageEnglish
+	}
+
+	switch method {
+	case entities.Method, entities.SentimentMethod:
+		return jsonResponse(map[string]interface{}{
+			"entities": a.entityList(method == entities.SentimentMethod),
+			"language": language,
+		})
+
+	case sentiment.Method:
+		return jsonResponse(map[string]interface{}{
+			"documentSentiment": a.documentSentiment(),
+			"language":          language,
+			"sentences":         a.sentenceList(),
+		})
+
+	case syntax.Method:
+		return jsonResponse(map[string]interface{}{
+			"sentences": a.syntaxSentenceList(),
+			"tokens":    a.tokenList(),
+			"language":  language,
+		})
+
+	case classify.Method:
+		return jsonResponse(map[string]interface{}{
+			"categories": []classify.Category{},
+		})
+
+	case annotate.Method:
+		resp := map[string]interface{}{
+			"language": language,
+		}
+		f := req.Features
+		if f.ExtractSyntax {
+			resp["sentences"] = a.syntaxSentenceList()
+			resp["tokens"] = a.tokenList()
+		}
+		if f.ExtractEntities {
+			resp["entities"] = a.entityList(false)
+		}
+		if f.ExtractDocumentSentiment {
+			resp["documentSentiment"] = a.documentSentiment()
+			resp["sentences"] = a.sentenceList()
+		}
+		return jsonResponse(resp)
+	}
+
+	return errorResponse(http.StatusNotFound, "gcnltest: unknown method "+method)
+}
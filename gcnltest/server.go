@@ -0,0 +1,297 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package gcnltest provides a fake Natural Language API server for testing
+// code built on go-gcnl without network access.
+//
+// A Server answers each request with, in order of precedence, a queued
+// failure, a queued scripted response, a heuristic analysis of the document
+// if enabled, or an empty successful response:
+//
+//	srv := gcnltest.NewServer()
+//	defer srv.Close()
+//	srv.SetHeuristic(true)
+//
+//	entityMap, err := entities.NewClientRequest(srv.Client()).FromPlainText("Larry Page founded Google.")
+package gcnltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/annotate"
+)
+
+// AnyMethod may be given instead of a method name to script the responses of
+// every method.
+const AnyMethod = "*"
+
+// TestKey is the API key of the clients returned by Server.Client.
+const TestKey = "gcnltest-key"
+
+// A Request is a request received by a Server.
+type Request struct {
+	Method  string // API method, for example "analyzeEntities"
+	Version string // API version, for example "v1beta1"
+	Header  http.Header
+	Body    []byte
+
+	// Document is the document of the request, decoded from Body.
+	Document Document
+}
+
+// A Document is the JSON representation of a gcnl.Document.
+type Document struct {
+	Type          gcnl.Type `json:"type"`
+	Language      string    `json:"language"`
+	Content       string    `json:"content"`
+	GCSContentURI string    `json:"gcsContentUri"`
+}
+
+// requestBody is the union of the request bodies of all methods.
+type requestBody struct {
+	Document     Document          `json:"document"`
+	EncodingType gcnl.Encoding     `json:"encodingType"`
+	Features     annotate.Features `json:"features"`
+}
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// A Server is a fake Natural Language API server. It is safe for concurrent
+// use.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	latency   time.Duration
+	heuristic bool
+	responses map[string][]response
+	failures  map[string][]int
+	requests  []Request
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		responses: make(map[string][]response),
+		failures:  make(map[string][]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a gcnl.Client sending its requests to the server.
+func (s *Server) Client() *gcnl.Client {
+	c := gcnl.NewClient(TestKey)
+	c.BaseURL = s.URL
+	c.HTTPClient = s.Server.Client()
+	return c
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetHeuristic enables or disables the heuristic analysis of documents for
+// requests without a scripted response. The analysis is deterministic:
+// sentences end with ".", "!" or "?", every run of capitalized words is an
+// entity of type OTHER, and sentiment is scored from a short list of positive
+// and negative words.
+func (s *Server) SetHeuristic(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heuristic = enabled
+}
+
+// Respond queues a response with the given status and body for the next
+// request to method. Queued responses are used once, in order.
+func (s *Server) Respond(method string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[method] = append(s.responses[method], response{status, []byte(body)})
+}
+
+// RespondJSON is like Respond but answers 200 OK with v serialized into JSON.
+func (s *Server) RespondJSON(method string, v interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.Respond(method, http.StatusOK, string(d))
+	return nil
+}
+
+// Fail makes the next n requests to method fail with the given HTTP status
+// code and a matching Google error envelope. Failures take precedence over
+// scripted responses.
+func (s *Server) Fail(method string, code, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures[method] = append(s.failures[method], code)
+	}
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset forgets the received requests and any queued responses and failures.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.responses = make(map[string][]response)
+	s.failures = make(map[string][]int)
+}
+
+// next pops the queued failure or response for method. ok is false if none is
+// queued.
+func (s *Server) next(method string) (resp response, ok bool) {
+	for _, m := range []string{method, AnyMethod} {
+		if codes := s.failures[m]; len(codes) > 0 {
+			s.failures[m] = codes[1:]
+			return errorResponse(codes[0], ""), true
+		}
+	}
+	for _, m := range []string{method, AnyMethod} {
+		if rs := s.responses[m]; len(rs) > 0 {
+			s.responses[m] = rs[1:]
+			return rs[0], true
+		}
+	}
+	return
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// Paths are of the form /{version}/documents:{method}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], "documents:") {
+		writeResponse(w, errorResponse(http.StatusNotFound, "unknown path "+r.URL.Path))
+		return
+	}
+	version, method := parts[0], strings.TrimPrefix(parts[1], "documents:")
+
+	body, _ := ioutil.ReadAll(r.Body)
+	var reqBody requestBody
+	decodeErr := json.Unmarshal(body, &reqBody)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method:   method,
+		Version:  version,
+		Header:   r.Header.Clone(),
+		Body:     body,
+		Document: reqBody.Document,
+	})
+	latency, heuristic := s.latency, s.heuristic
+	resp, scripted := s.next(method)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		select {
+		case <-r.Context().Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+
+	switch {
+	case scripted:
+	case r.Method != "POST":
+		resp = errorResponse(http.StatusMethodNotAllowed, "")
+	case len(r.Header.Get("X-Goog-Api-Key")) == 0 && len(r.Header.Get("Authorization")) == 0 && len(r.URL.Query().Get("key")) == 0:
+		resp = errorResponse(http.StatusUnauthorized, "Request is missing required authentication credential.")
+	case decodeErr != nil:
+		resp = errorResponse(http.StatusBadRequest, "Invalid JSON payload received. "+decodeErr.Error())
+	case !heuristic:
+		resp = response{http.StatusOK, []byte("{}")}
+	default:
+		resp = analyze(method, &reqBody)
+	}
+
+	writeResponse(w, resp)
+}
+
+func writeResponse(w http.ResponseWriter, resp response) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+// statuses maps HTTP status codes to the canonical error status of the API.
+var statuses = map[int]string{
+	http.StatusBadRequest:          gcnl.StatusInvalidArgument,
+	http.StatusUnauthorized:        gcnl.StatusUnauthenticated,
+	http.StatusForbidden:           gcnl.StatusPermissionDenied,
+	http.StatusNotFound:            "NOT_FOUND",
+	http.StatusTooManyRequests:     gcnl.StatusResourceExhausted,
+	http.StatusInternalServerError: "INTERNAL",
+	http.StatusNotImplemented:      "UNIMPLEMENTED",
+	http.StatusServiceUnavailable:  gcnl.StatusUnavailable,
+	http.StatusGatewayTimeout:      "DEADLINE_EXCEEDED",
+}
+
+// errorResponse returns a response with the Google error envelope for code.
+func errorResponse(code int, message string) response {
+	if len(message) == 0 {
+		message = http.StatusText(code)
+	}
+	status, ok := statuses[code]
+	if !ok {
+		status = "UNKNOWN"
+	}
+
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+			"status":  status,
+		},
+	})
+	return response{code, buf.Bytes()}
+}
+
+// jsonResponse returns a 200 OK response with v serialized into JSON.
+func jsonResponse(v interface{}) response {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return errorResponse(http.StatusInternalServerError, fmt.Sprint("gcnltest: ", err))
+	}
+	return response{http.StatusOK, d}
+}
@@ -0,0 +1,229 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnltest_test
+
+import (
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/annotate"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+	"github.com/jlubawy/go-gcnl/sentiment"
+	"github.com/jlubawy/go-gcnl/syntax"
+)
+
+const content = "Larry Page founded Google in California. The company is great! Larry Page likes 😀 Google."
+
+func TestHeuristicEntities(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	doc := gcnl.NewPlainTextDocument(content)
+	for _, enc := range []gcnl.Encoding{gcnl.EncodingUTF8, gcnl.EncodingUTF16, gcnl.EncodingUTF32} {
+		m, err := entities.NewClientRequest(srv.Client()).WithEncoding(enc).FromDocument(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		es := m.Entities()
+		if len(es) != 3 {
+			t.Fatalf("%s: got %d entities, want 3", enc, len(es))
+		}
+		if es[0].Name != "Google" || len(es[0].Mentions) != 2 || es[0].Salience != 0.4 {
+			t.Errorf("%s: got most salient entity %+v", enc, es[0])
+		}
+		for _, e := range es {
+			for _, mention := range e.Mentions {
+				text, err := mention.Text(doc)
+				if err != nil || text != mention.TextSpan.Content {
+					t.Errorf("%s: mention %+v covers %q (%v)", enc, mention.TextSpan, text, err)
+				}
+			}
+		}
+	}
+}
+
+func TestHeuristicSentiment(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	res, err := sentiment.NewClientRequest(srv.Client()).FromPlainText(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Sentences) != 3 {
+		t.Fatalf("got %d sentences, want 3", len(res.Sentences))
+	}
+	if s := res.Sentences[0].Sentiment; s.Score != 0 {
+		t.Errorf("neutral sentence has score %v", s.Score)
+	}
+	if s := res.Sentences[1].Sentiment; s.Score != 1 || s.Polarity != 1 {
+		t.Errorf("positive sentence has score %v and polarity %v", s.Score, s.Polarity)
+	}
+	if res.DocumentSentiment.Score <= 0 {
+		t.Errorf("positive document has score %v", res.DocumentSentiment.Score)
+	}
+}
+
+func TestHeuristicEntitySentiment(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	m, err := entities.NewClientSentimentRequest(srv.Client()).FromPlainText("Bob is great. Bob is terrible. Bob is awful.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := m.Entities()
+	if len(es) != 1 || len(es[0].Mentions) != 3 || es[0].Sentiment == nil {
+		t.Fatalf("got entities %+v", es)
+	}
+
+	// The entity sentiment averages the sentiment of its mentions
+	var want sentiment.Sentiment
+	for _, mention := range es[0].Mentions {
+		want.Score += mention.Sentiment.Score / 3
+		want.Polarity += mention.Sentiment.Polarity / 3
+		want.Magnitude += mention.Sentiment.Magnitude / 3
+	}
+	got := *es[0].Sentiment
+	if math.Abs(got.Score-want.Score) > 1e-9 || math.Abs(got.Polarity-want.Polarity) > 1e-9 ||
+		math.Abs(got.Magnitude-want.Magnitude) > 1e-9 {
+		t.Errorf("got sentiment %+v, want %+v", got, want)
+	}
+}
+
+func TestHeuristicSyntax(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	res, err := syntax.NewClientRequest(srv.Client()).FromPlainText(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(res.Tokens.Roots()); n != len(res.Sentences) || n != 3 {
+		t.Errorf("got %d roots and %d sentences, want 3", n, len(res.Sentences))
+	}
+	if v := srv.Requests()[0].Version; v != "v1beta2" {
+		t.Errorf("analyzeSyntax sent to %s, want v1beta2", v)
+	}
+}
+
+func TestHeuristicAnnotate(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	res, err := annotate.NewClientRequest(srv.Client(), annotate.AllFeatures).FromPlainText(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entities) != 3 || len(res.Tokens) == 0 || res.DocumentSentiment == nil {
+		t.Errorf("got incomplete result %+v", res)
+	}
+}
+
+func TestScriptedResponses(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	srv.Respond(entities.Method, http.StatusOK, `{"entities":[{"name":"Ada","type":"PERSON"}]}`)
+	srv.Fail(gcnltest.AnyMethod, http.StatusServiceUnavailable, 1)
+	req := entities.NewClientRequest(srv.Client())
+
+	// Failures come first
+	_, err := req.FromPlainText("Larry Page founded Google.")
+	apiErr, ok := err.(*gcnl.APIError)
+	if !ok || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Status != gcnl.StatusUnavailable {
+		t.Fatalf("got error %v, want 503 UNAVAILABLE", err)
+	}
+
+	// Then scripted responses
+	m, err := req.FromPlainText("Larry Page founded Google.")
+	if err != nil || len(m[entities.TypePerson]) != 1 || m[entities.TypePerson][0].Name != "Ada" {
+		t.Fatalf("got %v %v, want the scripted response", m, err)
+	}
+
+	// Then the heuristic analysis
+	m, err = req.FromPlainText("Larry Page founded Google.")
+	if err != nil || m.Len() != 2 {
+		t.Fatalf("got %v %v, want the heuristic analysis", m, err)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+
+	doc, err := gcnl.WithLanguage(gcnl.NewPlainTextDocument("Hola."), "es")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entities.NewClientRequest(srv.Client()).FromDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	r := reqs[0]
+	if r.Method != entities.Method || r.Version != string(gcnl.VersionDefault) {
+		t.Errorf("got request to %s %s", r.Version, r.Method)
+	}
+	if r.Header.Get("X-Goog-Api-Key") != gcnltest.TestKey {
+		t.Error("request is missing the API key")
+	}
+	if r.Document.Content != "Hola." || r.Document.Language != "es" || r.Document.Type != gcnl.TypePlainText {
+		t.Errorf("got document %+v", r.Document)
+	}
+
+	srv.Reset()
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("got %d requests after Reset, want 0", n)
+	}
+}
+
+func TestUnauthenticated(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	c.HTTPClient = &http.Client{Transport: stripAuth{srv.Server.Client().Transport}}
+
+	_, err := entities.NewClientRequest(c).FromPlainText("Larry Page founded Google.")
+	if !gcnl.IsUnauthenticated(err) {
+		t.Errorf("got error %v, want unauthenticated", err)
+	}
+}
+
+// stripAuth removes the API key from requests.
+type stripAuth struct{ http.RoundTripper }
+
+func (rt stripAuth) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Del("X-Goog-Api-Key")
+	return rt.RoundTripper.RoundTrip(r)
+}
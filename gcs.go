@@ -0,0 +1,60 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidGCSURI = errors.New(`gcnl: Google Cloud Storage URI must be of the form "gs://bucket/object"`)
+
+// A gcsDocument is a Document whose content is stored in Google Cloud Storage
+// and read by the API directly.
+type gcsDocument interface {
+	GCSContentURI() string
+}
+
+// A GCSDocument is a Document whose content is read by the API from a Google
+// Cloud Storage object instead of being sent with the request. Content always
+// returns an empty string.
+type GCSDocument struct {
+	uri      string
+	typ      Type
+	language string
+}
+
+func (doc *GCSDocument) Type() Type            { return doc.typ }
+func (doc *GCSDocument) Language() string      { return doc.language }
+func (doc *GCSDocument) Content() string       { return "" }
+func (doc *GCSDocument) GCSContentURI() string { return doc.uri }
+
+// NewGCSDocument returns a Document of the given type whose content is the
+// Google Cloud Storage object at uri, for example "gs://bucket/article.html".
+func NewGCSDocument(uri string, typ Type) (Document, error) {
+	name := strings.TrimPrefix(uri, "gs://")
+	if name == uri || strings.Index(name, "/") < 1 || strings.HasSuffix(name, "/") {
+		return nil, ErrInvalidGCSURI
+	}
+	return &GCSDocument{uri, typ, LanguageEnglish}, nil
+}
+
+// MarshalJSON satisfies the json.Marshaler interface for GCSDocument.
+func (doc *GCSDocument) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(doc)
+}
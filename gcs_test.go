@@ -0,0 +1,87 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewGCSDocument(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"gs://bucket/object", true},
+		{"gs://bucket/dir/article.html", true},
+		{"gs://b/o", true},
+		{"", false},
+		{"bucket/object", false},
+		{"gs://", false},
+		{"gs://bucket", false},
+		{"gs://bucket/", false},
+		{"gs://bucket/dir/", false},
+		{"gs:///object", false},
+		{"GS://bucket/object", false},
+		{"https://storage.googleapis.com/bucket/object", false},
+	}
+
+	for _, tt := range tests {
+		doc, err := NewGCSDocument(tt.uri, TypePlainText)
+		if !tt.valid {
+			if err != ErrInvalidGCSURI {
+				t.Errorf("NewGCSDocument(%q) returned %v, want ErrInvalidGCSURI", tt.uri, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewGCSDocument(%q) returned %v", tt.uri, err)
+			continue
+		}
+		if uri := doc.(*GCSDocument).GCSContentURI(); uri != tt.uri {
+			t.Errorf("NewGCSDocument(%q) has URI %q", tt.uri, uri)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	gcsDoc, err := NewGCSDocument("gs://bucket/article.html", TypeHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		doc  Document
+		want string
+	}{
+		{gcsDoc, `{"type":"HTML","language":"en","gcsContentUri":"gs://bucket/article.html"}`},
+		{NewPlainTextDocument("Hello"), `{"type":"PLAIN_TEXT","language":"en","content":"Hello"}`},
+		// Empty content is still sent, since the API requires a source
+		{NewPlainTextDocument(""), `{"type":"PLAIN_TEXT","language":"en","content":""}`},
+	}
+
+	for _, tt := range tests {
+		d, err := json.Marshal(tt.doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(d) != tt.want {
+			t.Errorf("%T marshals to %s, want %s", tt.doc, d, tt.want)
+		}
+	}
+}
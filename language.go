@@ -0,0 +1,134 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"errors"
+	"strings"
+)
+
+// LanguageAuto lets the API detect the language of a document.
+const LanguageAuto = ""
+
+var ErrInvalidLanguage = errors.New("gcnl: invalid BCP-47 language tag")
+
+// ValidLanguage reports whether tag is a well-formed BCP-47 language tag such
+// as "en", "es-419" or "zh-Hant-TW", or LanguageAuto. It only checks the
+// syntax of the tag, not whether the API supports the language.
+func ValidLanguage(tag string) bool {
+	if tag == LanguageAuto {
+		return true
+	}
+
+	subtags := strings.Split(tag, "-")
+
+	// Private use tags such as "x-klingon"
+	if strings.EqualFold(subtags[0], "x") {
+		return len(subtags) > 1 && allSubtags(subtags[1:], 1, 8)
+	}
+
+	// Primary language subtag
+	if l := len(subtags[0]); !isAlpha(subtags[0]) || l < 2 || l > 8 || l == 4 {
+		return false
+	}
+
+	for i := 1; i < len(subtags); i++ {
+		st := subtags[i]
+		if len(st) == 0 || len(st) > 8 || !isAlnum(st) {
+			return false
+		}
+
+		// Extension and private use singletons must be followed by at
+		// least one subtag
+		if len(st) == 1 {
+			if i == len(subtags)-1 {
+				return false
+			}
+			if strings.EqualFold(st, "x") {
+				return allSubtags(subtags[i+1:], 1, 8)
+			}
+			if !allSubtags(subtags[i+1:i+2], 2, 8) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func allSubtags(subtags []string, min, max int) bool {
+	for _, st := range subtags {
+		if len(st) < min || len(st) > max || !isAlnum(st) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// WithLanguage returns a copy of doc whose language is tag. Use LanguageAuto
+// to let the API detect the language. ErrInvalidLanguage is returned if tag is
+// not a well-formed BCP-47 language tag.
+func WithLanguage(doc Document, tag string) (Document, error) {
+	if !ValidLanguage(tag) {
+		return nil, ErrInvalidLanguage
+	}
+
+	switch d := doc.(type) {
+	case *PlainTextDocument:
+		return &PlainTextDocument{d.content, tag}, nil
+	case *HTMLDocument:
+		return &HTMLDocument{d.content, tag}, nil
+	case *GCSDocument:
+		return &GCSDocument{d.uri, d.typ, tag}, nil
+	case *languageDocument:
+		return &languageDocument{d.Document, tag}, nil
+	}
+	return &languageDocument{doc, tag}, nil
+}
+
+// A languageDocument overrides the language of a Document of a type unknown to
+// WithLanguage.
+type languageDocument struct {
+	Document
+	language string
+}
+
+func (doc *languageDocument) Language() string { return doc.language }
+
+// MarshalJSON satisfies the json.Marshaler interface for languageDocument.
+func (doc *languageDocument) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(doc)
+}
@@ -0,0 +1,108 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidLanguage(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"", true},
+		{"en", true},
+		{"EN-us", true},
+		{"es-419", true},
+		{"zh-Hant-TW", true},
+		{"sl-rozaj-biske", true},
+		{"de-DE-u-co-phonebk", true},
+		{"en-US-x-twain", true},
+		{"x-klingon", true},
+		{"en-", false},
+		{"-en", false},
+		{"en_US", false},
+		{"en-US-x", false},
+		{"en-a", false},
+		{"en-a-b", false},
+		{"x", false},
+		{"e", false},
+		{"engl", false},
+		{"en-toolongsubtag", false},
+		{"e1", false},
+		{"en US", false},
+	}
+
+	doc := NewPlainTextDocument("Hola")
+	for _, tt := range tests {
+		if got := ValidLanguage(tt.tag); got != tt.want {
+			t.Errorf("ValidLanguage(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+
+		d, err := WithLanguage(doc, tt.tag)
+		switch {
+		case tt.want && err != nil:
+			t.Errorf("WithLanguage(%q) returned %v", tt.tag, err)
+		case tt.want && d.Language() != tt.tag:
+			t.Errorf("WithLanguage(%q) has language %q", tt.tag, d.Language())
+		case !tt.want && err != ErrInvalidLanguage:
+			t.Errorf("WithLanguage(%q) returned %v, want ErrInvalidLanguage", tt.tag, err)
+		}
+	}
+}
+
+// otherDocument is a Document of a type unknown to WithLanguage.
+type otherDocument struct{ PlainTextDocument }
+
+func TestWithLanguageJSON(t *testing.T) {
+	gcsDoc, err := NewGCSDocument("gs://bucket/article.html", TypeHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := &otherDocument{PlainTextDocument{"Bonjour", LanguageEnglish}}
+
+	tests := []struct {
+		doc  Document
+		tag  string
+		want string
+	}{
+		{NewPlainTextDocument("Hola"), "es", `{"type":"PLAIN_TEXT","language":"es","content":"Hola"}`},
+		{NewPlainTextDocument("Hola"), LanguageAuto, `{"type":"PLAIN_TEXT","content":"Hola"}`},
+		{&HTMLDocument{"Hola", LanguageEnglish}, LanguageAuto, `{"type":"HTML","content":"Hola"}`},
+		{gcsDoc, "es-419", `{"type":"HTML","language":"es-419","gcsContentUri":"gs://bucket/article.html"}`},
+		{gcsDoc, LanguageAuto, `{"type":"HTML","gcsContentUri":"gs://bucket/article.html"}`},
+		{other, "fr", `{"type":"PLAIN_TEXT","language":"fr","content":"Bonjour"}`},
+		{other, LanguageAuto, `{"type":"PLAIN_TEXT","content":"Bonjour"}`},
+	}
+
+	for _, tt := range tests {
+		doc, err := WithLanguage(tt.doc, tt.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(d) != tt.want {
+			t.Errorf("WithLanguage(%T, %q) marshals to %s, want %s", tt.doc, tt.tag, d, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// UnitSize is the number of characters billed as a single text unit.
+const UnitSize = 1000
+
+var (
+	ErrRateLimited    = errors.New("gcnl: client-side rate limit exceeded")
+	ErrBudgetExceeded = errors.New("gcnl: text unit budget exceeded")
+)
+
+// TextUnits returns the number of text units a request for doc is billed for,
+// that is one unit per started block of UnitSize characters, and at least one.
+// Documents stored in Google Cloud Storage count as a single unit since their
+// content is not known to the client.
+func TextUnits(doc Document) int {
+	n := utf8.RuneCountInString(doc.Content())
+	if n <= UnitSize {
+		return 1
+	}
+	return (n + UnitSize - 1) / UnitSize
+}
+
+// A DocumentRequest is a request body that analyzes a single Document. The
+// request types of all method packages implement it.
+type DocumentRequest interface {
+	Document() Document
+}
+
+// A Limiter throttles the requests sent by a Client using a token bucket for
+// the number of requests per second and another for the number of text units
+// per minute. A Limiter may be shared by several Clients and is safe for
+// concurrent use.
+type Limiter struct {
+	// FailFast makes Wait return ErrRateLimited instead of blocking when a
+	// request would exceed a rate.
+	FailFast bool
+
+	// Budget is the total number of text units that may be consumed. If
+	// zero, the number of units is not capped. Once exhausted every request
+	// fails with ErrBudgetExceeded.
+	Budget int64
+
+	mu       sync.Mutex
+	requests *bucket
+	units    *bucket
+	stats    LimiterStats
+}
+
+// LimiterStats are the counters of a Limiter.
+type LimiterStats struct {
+	Requests int64 // number of requests allowed
+	Units    int64 // number of text units consumed by allowed requests
+}
+
+// NewLimiter returns a Limiter allowing requestsPerSecond requests per second
+// and unitsPerMinute text units per minute. A value of zero or less disables
+// the corresponding limit.
+func NewLimiter(requestsPerSecond float64, unitsPerMinute int) *Limiter {
+	l := new(Limiter)
+	if requestsPerSecond > 0 {
+		l.requests = newBucket(math.Max(1, math.Ceil(requestsPerSecond)), requestsPerSecond)
+	}
+	if unitsPerMinute > 0 {
+		l.units = newBucket(float64(unitsPerMinute), float64(unitsPerMinute)/60)
+	}
+	return l
+}
+
+// Wait blocks until a request consuming the given number of text units is
+// allowed by the limiter, or ctx is done. If FailFast is set it returns
+// ErrRateLimited instead of blocking.
+func (l *Limiter) Wait(ctx context.Context, units int) error {
+	for {
+		l.mu.Lock()
+		if l.Budget > 0 && l.stats.Units+int64(units) > l.Budget {
+			l.mu.Unlock()
+			return ErrBudgetExceeded
+		}
+
+		now := time.Now()
+		wait := l.requests.wait(now, 1)
+		if d := l.units.wait(now, float64(units)); d > wait {
+			wait = d
+		}
+		if wait == 0 {
+			l.requests.take(1)
+			l.units.take(float64(units))
+			l.stats.Requests++
+			l.stats.Units += int64(units)
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if l.FailFast {
+			return ErrRateLimited
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Stats returns the counters of the limiter.
+func (l *Limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// A bucket is a token bucket. A nil bucket allows everything.
+type bucket struct {
+	capacity float64
+	rate     float64 // tokens added per second
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(capacity, rate float64) *bucket {
+	return &bucket{
+		capacity: capacity,
+		rate:     rate,
+		tokens:   capacity,
+		last:     time.Now(),
+	}
+}
+
+// wait refills the bucket and returns how long until n tokens can be taken.
+// Requests larger than the capacity are allowed once the bucket is full.
+func (b *bucket) wait(now time.Time, n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+
+	need := math.Min(n, b.capacity)
+	if b.tokens >= need {
+		return 0
+	}
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+// take removes n tokens from the bucket, which may leave it in debt.
+func (b *bucket) take(n float64) {
+	if b != nil {
+		b.tokens -= n
+	}
+}
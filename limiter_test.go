@@ -0,0 +1,185 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+func TestTextUnits(t *testing.T) {
+	gcsDoc, err := gcnl.NewGCSDocument("gs://bucket/object.txt", gcnl.TypePlainText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		doc  gcnl.Document
+		want int
+	}{
+		{gcnl.NewPlainTextDocument(""), 1},
+		{gcnl.NewPlainTextDocument(strings.Repeat("a", gcnl.UnitSize)), 1},
+		{gcnl.NewPlainTextDocument(strings.Repeat("a", gcnl.UnitSize+1)), 2},
+		{gcnl.NewPlainTextDocument(strings.Repeat("é", 2*gcnl.UnitSize+1)), 3},
+		{gcsDoc, 1},
+	}
+	for i, tt := range tests {
+		if got := gcnl.TextUnits(tt.doc); got != tt.want {
+			t.Errorf("%d: got %d units, want %d", i, got, tt.want)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	type wait struct {
+		units int
+		want  error
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		limiter   func() *gcnl.Limiter
+		ctx       context.Context
+		waits     []wait
+		wantStats gcnl.LimiterStats
+	}{
+		{
+			name: "budget",
+			limiter: func() *gcnl.Limiter {
+				l := gcnl.NewLimiter(0, 0)
+				l.Budget = 3
+				return l
+			},
+			waits:     []wait{{2, nil}, {2, gcnl.ErrBudgetExceeded}, {1, nil}, {1, gcnl.ErrBudgetExceeded}},
+			wantStats: gcnl.LimiterStats{Requests: 2, Units: 3},
+		},
+		{
+			name: "requests per second",
+			limiter: func() *gcnl.Limiter {
+				l := gcnl.NewLimiter(1, 0)
+				l.FailFast = true
+				return l
+			},
+			waits:     []wait{{1, nil}, {1, gcnl.ErrRateLimited}},
+			wantStats: gcnl.LimiterStats{Requests: 1, Units: 1},
+		},
+		{
+			name: "units per minute",
+			limiter: func() *gcnl.Limiter {
+				l := gcnl.NewLimiter(0, 10)
+				l.FailFast = true
+				return l
+			},
+			waits:     []wait{{4, nil}, {6, nil}, {1, gcnl.ErrRateLimited}},
+			wantStats: gcnl.LimiterStats{Requests: 2, Units: 10},
+		},
+		{
+			name: "oversized request",
+			limiter: func() *gcnl.Limiter {
+				l := gcnl.NewLimiter(0, 10)
+				l.FailFast = true
+				return l
+			},
+			waits:     []wait{{25, nil}, {1, gcnl.ErrRateLimited}},
+			wantStats: gcnl.LimiterStats{Requests: 1, Units: 25},
+		},
+		{
+			name:      "canceled",
+			limiter:   func() *gcnl.Limiter { return gcnl.NewLimiter(1, 0) },
+			ctx:       canceled,
+			waits:     []wait{{1, nil}, {1, context.Canceled}},
+			wantStats: gcnl.LimiterStats{Requests: 1, Units: 1},
+		},
+		{
+			name:      "unlimited",
+			limiter:   func() *gcnl.Limiter { return gcnl.NewLimiter(0, 0) },
+			waits:     []wait{{1000, nil}, {1000, nil}, {1000, nil}},
+			wantStats: gcnl.LimiterStats{Requests: 3, Units: 3000},
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := tt.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		l := tt.limiter()
+		for i, w := range tt.waits {
+			if err := l.Wait(ctx, w.units); err != w.want {
+				t.Errorf("%s: wait %d: got error %v, want %v", tt.name, i, err, w.want)
+			}
+		}
+		if got := l.Stats(); got != tt.wantStats {
+			t.Errorf("%s: got stats %+v, want %+v", tt.name, got, tt.wantStats)
+		}
+	}
+}
+
+func TestLimiterChargesRetries(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	// 1500 characters are billed as 2 units per attempt
+	doc := gcnl.NewPlainTextDocument(strings.Repeat("Larry Page founded Google. ", 60)[:1500])
+
+	tests := []struct {
+		name      string
+		failures  int
+		budget    int64
+		wantErr   error
+		wantSent  int
+		wantStats gcnl.LimiterStats
+	}{
+		{"no retry", 0, 0, nil, 1, gcnl.LimiterStats{Requests: 1, Units: 2}},
+		{"two retries", 2, 0, nil, 3, gcnl.LimiterStats{Requests: 3, Units: 6}},
+		{"budget exhausted by retries", 2, 5, gcnl.ErrBudgetExceeded, 2, gcnl.LimiterStats{Requests: 2, Units: 4}},
+	}
+
+	for _, tt := range tests {
+		srv.Reset()
+		srv.Fail(entities.Method, http.StatusServiceUnavailable, tt.failures)
+
+		var retries []gcnl.RetryInfo
+		c := srv.Client()
+		c.Retry = fastRetries(&retries)
+		c.Limiter = gcnl.NewLimiter(0, 0)
+		c.Limiter.Budget = tt.budget
+
+		_, err := entities.NewClientRequest(c).FromDocument(doc)
+		if err != tt.wantErr {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if n := len(srv.Requests()); n != tt.wantSent {
+			t.Errorf("%s: sent %d requests, want %d", tt.name, n, tt.wantSent)
+		}
+		if got := c.Limiter.Stats(); got != tt.wantStats {
+			t.Errorf("%s: got stats %+v, want %+v", tt.name, got, tt.wantStats)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	ErrNoOffsets     = errors.New("gcnl: offsets are not computed with EncodingNone")
+	ErrInvalidOffset = errors.New("gcnl: offset out of range")
+)
+
+// runeLen returns the length of r in the units of enc.
+func runeLen(r rune, enc Encoding) int {
+	switch enc {
+	case EncodingUTF16:
+		if r >= 0x10000 {
+			return 2
+		}
+		return 1
+	case EncodingUTF32:
+		return 1
+	}
+	return utf8.RuneLen(r)
+}
+
+// EncodedLen returns the length of s in the units of enc: bytes for UTF-8,
+// 16-bit code units for UTF-16 and runes for UTF-32.
+func EncodedLen(s string, enc Encoding) int {
+	if enc == EncodingUTF8 {
+		return len(s)
+	}
+	n := 0
+	for _, r := range s {
+		n += runeLen(r, enc)
+	}
+	return n
+}
+
+// ByteOffset converts an offset computed by the API using enc, such as
+// TextSpan.BeginOffset, into a byte offset of content, the Go string index.
+// ErrInvalidOffset is returned if offset is out of range or falls in the middle
+// of a character.
+func ByteOffset(content string, offset int, enc Encoding) (int, error) {
+	if enc == EncodingNone {
+		return -1, ErrNoOffsets
+	}
+	if offset < 0 {
+		return -1, ErrInvalidOffset
+	}
+	if enc == EncodingUTF8 {
+		if offset > len(content) || (offset < len(content) && !utf8.RuneStart(content[offset])) {
+			return -1, ErrInvalidOffset
+		}
+		return offset, nil
+	}
+
+	n := 0
+	for i, r := range content {
+		if n == offset {
+			return i, nil
+		}
+		if n > offset {
+			return -1, ErrInvalidOffset
+		}
+		n += runeLen(r, enc)
+	}
+	if n == offset {
+		return len(content), nil
+	}
+	return -1, ErrInvalidOffset
+}
+
+// RuneOffset is like ByteOffset but returns the rune offset of content, that
+// is the number of runes before offset.
+func RuneOffset(content string, offset int, enc Encoding) (int, error) {
+	i, err := ByteOffset(content, offset, enc)
+	if err != nil {
+		return -1, err
+	}
+	return utf8.RuneCountInString(content[:i]), nil
+}
+
+// EncodedOffset is the inverse of ByteOffset. It converts a byte offset of
+// content into an offset in the units of enc.
+func EncodedOffset(content string, byteOffset int, enc Encoding) (int, error) {
+	if enc == EncodingNone {
+		return -1, ErrNoOffsets
+	}
+	if byteOffset < 0 || byteOffset > len(content) ||
+		(byteOffset < len(content) && !utf8.RuneStart(content[byteOffset])) {
+		return -1, ErrInvalidOffset
+	}
+	return EncodedLen(content[:byteOffset], enc), nil
+}
+
+func (ts TextSpan) encoding() Encoding {
+	if len(ts.Encoding) == 0 {
+		return EncodingUTF8
+	}
+	return ts.Encoding
+}
+
+// End returns the offset just past the end of the span, in the same units as
+// BeginOffset. It returns -1 if offsets were not computed.
+func (ts TextSpan) End() int {
+	if ts.BeginOffset < 0 || ts.encoding() == EncodingNone {
+		return -1
+	}
+	return ts.BeginOffset + EncodedLen(ts.Content, ts.encoding())
+}
+
+// ByteRange returns the byte offsets of the beginning and end of the span in
+// content, the content of the analyzed document.
+func (ts TextSpan) ByteRange(content string) (begin, end int, err error) {
+	if ts.BeginOffset < 0 {
+		return -1, -1, ErrNoOffsets
+	}
+	if begin, err = ByteOffset(content, ts.BeginOffset, ts.encoding()); err != nil {
+		return -1, -1, err
+	}
+	end = begin + len(ts.Content)
+	if end > len(content) {
+		return -1, -1, ErrInvalidOffset
+	}
+	return
+}
+
+// RuneRange is like ByteRange but returns rune offsets.
+func (ts TextSpan) RuneRange(content string) (begin, end int, err error) {
+	b, e, err := ts.ByteRange(content)
+	if err != nil {
+		return -1, -1, err
+	}
+	begin = utf8.RuneCountInString(content[:b])
+	end = begin + utf8.RuneCountInString(content[b:e])
+	return
+}
+
+// Text returns the text of content covered by the span. It usually equals
+// Content, but is taken from the document itself so that it can be used to
+// verify offsets or to highlight the original content.
+func (ts TextSpan) Text(content string) (string, error) {
+	begin, end, err := ts.ByteRange(content)
+	if err != nil {
+		return "", err
+	}
+	return content[begin:end], nil
+}
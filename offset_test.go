@@ -0,0 +1,105 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import "testing"
+
+// offsetContent mixes 1, 2, 3 and 4 byte UTF-8 characters; "😀" is a surrogate pair
+// in UTF-16.
+const offsetContent = "aé€😀b"
+
+func TestEncodedLen(t *testing.T) {
+	for _, tt := range []struct {
+		enc  Encoding
+		want int
+	}{
+		{EncodingUTF8, 1 + 2 + 3 + 4 + 1},
+		{EncodingUTF16, 1 + 1 + 1 + 2 + 1},
+		{EncodingUTF32, 5},
+	} {
+		if got := EncodedLen(offsetContent, tt.enc); got != tt.want {
+			t.Errorf("EncodedLen(%s) = %d, want %d", tt.enc, got, tt.want)
+		}
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	// Byte offsets of each character, then of the end of the content
+	byteOffsets := []int{0, 1, 3, 6, 10, 11}
+
+	for _, tt := range []struct {
+		enc     Encoding
+		offsets []int
+	}{
+		{EncodingUTF8, []int{0, 1, 3, 6, 10, 11}},
+		{EncodingUTF16, []int{0, 1, 2, 3, 5, 6}},
+		{EncodingUTF32, []int{0, 1, 2, 3, 4, 5}},
+	} {
+		for i, offset := range tt.offsets {
+			got, err := ByteOffset(offsetContent, offset, tt.enc)
+			if err != nil || got != byteOffsets[i] {
+				t.Errorf("ByteOffset(%d, %s) = %d, %v, want %d", offset, tt.enc, got, err, byteOffsets[i])
+			}
+			back, err := EncodedOffset(offsetContent, byteOffsets[i], tt.enc)
+			if err != nil || back != offset {
+				t.Errorf("EncodedOffset(%d, %s) = %d, %v, want %d", byteOffsets[i], tt.enc, back, err, offset)
+			}
+		}
+		if _, err := ByteOffset(offsetContent, tt.offsets[len(tt.offsets)-1]+1, tt.enc); err != ErrInvalidOffset {
+			t.Errorf("%s: offset past the end returned %v, want ErrInvalidOffset", tt.enc, err)
+		}
+	}
+
+	// Offsets within a character
+	if _, err := ByteOffset(offsetContent, 2, EncodingUTF8); err != ErrInvalidOffset {
+		t.Errorf("UTF-8 offset within é returned %v, want ErrInvalidOffset", err)
+	}
+	if _, err := ByteOffset(offsetContent, 4, EncodingUTF16); err != ErrInvalidOffset {
+		t.Errorf("UTF-16 offset within a surrogate pair returned %v, want ErrInvalidOffset", err)
+	}
+	if _, err := ByteOffset(offsetContent, 0, EncodingNone); err != ErrNoOffsets {
+		t.Errorf("EncodingNone returned %v, want ErrNoOffsets", err)
+	}
+}
+
+func TestTextSpan(t *testing.T) {
+	for _, enc := range []Encoding{EncodingUTF8, EncodingUTF16, EncodingUTF32} {
+		begin, _ := EncodedOffset(offsetContent, 3, enc)
+		ts := TextSpan{Content: "€😀", BeginOffset: begin, Encoding: enc}
+
+		text, err := ts.Text(offsetContent)
+		if err != nil || text != "€😀" {
+			t.Errorf("%s: Text = %q, %v", enc, text, err)
+		}
+		if b, e, err := ts.ByteRange(offsetContent); err != nil || b != 3 || e != 10 {
+			t.Errorf("%s: ByteRange = %d, %d, %v, want 3, 10", enc, b, e, err)
+		}
+		if b, e, err := ts.RuneRange(offsetContent); err != nil || b != 2 || e != 4 {
+			t.Errorf("%s: RuneRange = %d, %d, %v, want 2, 4", enc, b, e, err)
+		}
+		if end := ts.End(); end != EncodedLen(offsetContent[:10], enc) {
+			t.Errorf("%s: End = %d", enc, end)
+		}
+	}
+
+	// An empty Encoding means UTF-8
+	ts := TextSpan{Content: "b", BeginOffset: 10}
+	if text, err := ts.Text(offsetContent); err != nil || text != "b" {
+		t.Errorf("Text = %q, %v, want b", text, err)
+	}
+}
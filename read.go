@@ -0,0 +1,155 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxSize is the default maximum size in bytes of documents read by
+// NewDocumentFromReader and friends. It matches the API document size limit.
+const DefaultMaxSize = 1000000
+
+var (
+	ErrDocumentTooLarge = errors.New("gcnl: document exceeds maximum size")
+	ErrNotText          = errors.New("gcnl: document content is not UTF-8 text")
+)
+
+// ReadOptions control how documents are built from raw content. A nil
+// *ReadOptions selects the defaults.
+type ReadOptions struct {
+	// Type is the type of the document. If TypeUnspecified, the type is
+	// sniffed from the content.
+	Type Type
+
+	// MaxSize is the maximum size in bytes of the content. If zero,
+	// DefaultMaxSize is used. If negative, the size is not limited.
+	MaxSize int64
+}
+
+func (opts *ReadOptions) maxSize() int64 {
+	if opts == nil || opts.MaxSize == 0 {
+		return DefaultMaxSize
+	}
+	return opts.MaxSize
+}
+
+func (opts *ReadOptions) typ() Type {
+	if opts == nil || len(opts.Type) == 0 {
+		return TypeUnspecified
+	}
+	return opts.Type
+}
+
+// SniffType returns TypeHTML if content looks like an HTML document and
+// TypePlainText otherwise.
+func SniffType(content []byte) Type {
+	ct := http.DetectContentType(content)
+	if strings.HasPrefix(ct, "text/html") {
+		return TypeHTML
+	}
+
+	// DetectContentType reports XHTML as XML
+	if strings.HasPrefix(ct, "text/xml") {
+		n := len(content)
+		if n > 512 {
+			n = 512
+		}
+		if bytes.Contains(bytes.ToLower(content[:n]), []byte("<html")) {
+			return TypeHTML
+		}
+	}
+
+	return TypePlainText
+}
+
+// NewDocumentFromBytes returns a plain text or HTML document with the given
+// content. ErrDocumentTooLarge is returned if content is larger than the
+// maximum size and ErrNotText if it is not valid UTF-8.
+func NewDocumentFromBytes(content []byte, opts *ReadOptions) (doc Document, err error) {
+	if max := opts.maxSize(); max > 0 && int64(len(content)) > max {
+		err = ErrDocumentTooLarge
+		return
+	}
+	if !utf8.Valid(content) {
+		err = ErrNotText
+		return
+	}
+
+	typ := opts.typ()
+	if typ == TypeUnspecified {
+		typ = SniffType(content)
+	}
+
+	switch typ {
+	case TypePlainText:
+		doc = &PlainTextDocument{string(content), LanguageEnglish}
+	case TypeHTML:
+		doc = &HTMLDocument{string(content), LanguageEnglish}
+	default:
+		err = fmt.Errorf("gcnl: unsupported document type %s", typ)
+	}
+	return
+}
+
+// NewDocumentFromReader is like NewDocumentFromBytes but reads the content
+// from r. Reading stops as soon as the maximum size is exceeded.
+func NewDocumentFromReader(r io.Reader, opts *ReadOptions) (doc Document, err error) {
+	if max := opts.maxSize(); max > 0 {
+		r = io.LimitReader(r, max+1)
+	}
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	return NewDocumentFromBytes(content, opts)
+}
+
+// NewDocumentFromFile is like NewDocumentFromReader but reads the content of
+// the file at path. Files with an .html, .htm or .xhtml extension are HTML
+// documents unless the type is set in opts.
+func NewDocumentFromFile(path string, opts *ReadOptions) (doc Document, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if opts.typ() == TypeUnspecified {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".html", ".htm", ".xhtml":
+			o := ReadOptions{Type: TypeHTML}
+			if opts != nil {
+				o.MaxSize = opts.MaxSize
+			}
+			opts = &o
+		}
+	}
+
+	return NewDocumentFromReader(f, opts)
+}
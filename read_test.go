@@ -0,0 +1,162 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSniffType(t *testing.T) {
+	tests := []struct {
+		content string
+		want    Type
+	}{
+		{"Hello world.", TypePlainText},
+		{"", TypePlainText},
+		{"<!DOCTYPE html><title>Hello</title>", TypeHTML},
+		{"<html><body>Hello</body></html>", TypeHTML},
+		{"  <p>Hello</p>", TypeHTML},
+		{`<?xml version="1.0" encoding="UTF-8"?><html xmlns="http://www.w3.org/1999/xhtml"><body>Hello</body></html>`, TypeHTML},
+		{`<?xml version="1.0"?><!DOCTYPE html><HTML><body>Hello</body></HTML>`, TypeHTML},
+		{`<?xml version="1.0"?><rss version="2.0"><channel/></rss>`, TypePlainText},
+		{`<?xml version="1.0"?>` + strings.Repeat(" ", 512) + `<html>`, TypePlainText},
+	}
+
+	for _, tt := range tests {
+		if got := SniffType([]byte(tt.content)); got != tt.want {
+			t.Errorf("SniffType(%.40q) = %s, want %s", tt.content, got, tt.want)
+		}
+	}
+}
+
+// endless is a reader that never runs out of content.
+type endless struct{ n int }
+
+func (r *endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	r.n += len(p)
+	return len(p), nil
+}
+
+func TestNewDocumentFromReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		opts     *ReadOptions
+		wantType Type
+		wantErr  error
+	}{
+		{name: "plain text", content: "Hello", wantType: TypePlainText},
+		{name: "sniffed HTML", content: "<p>Hello</p>", wantType: TypeHTML},
+		{name: "forced plain text", content: "<p>Hello</p>", opts: &ReadOptions{Type: TypePlainText}, wantType: TypePlainText},
+		{name: "forced HTML", content: "Hello", opts: &ReadOptions{Type: TypeHTML}, wantType: TypeHTML},
+		{name: "at max size", content: "Hello", opts: &ReadOptions{MaxSize: 5}, wantType: TypePlainText},
+		{name: "over max size", content: "Hello!", opts: &ReadOptions{MaxSize: 5}, wantErr: ErrDocumentTooLarge},
+		{name: "over default max size", content: strings.Repeat("a", DefaultMaxSize+1), wantErr: ErrDocumentTooLarge},
+		{name: "unlimited", content: strings.Repeat("a", DefaultMaxSize+1), opts: &ReadOptions{MaxSize: -1}, wantType: TypePlainText},
+		{name: "binary", content: "GIF89a\xff\x00", wantErr: ErrNotText},
+		{name: "Latin-1", content: "caf\xe9", wantErr: ErrNotText},
+	}
+
+	for _, tt := range tests {
+		doc, err := NewDocumentFromReader(strings.NewReader(tt.content), tt.opts)
+		if err != tt.wantErr {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if doc.Type() != tt.wantType || doc.Content() != tt.content {
+			t.Errorf("%s: got %s document %.40q", tt.name, doc.Type(), doc.Content())
+		}
+	}
+
+	if _, err := NewDocumentFromReader(strings.NewReader("Hello"), &ReadOptions{Type: Type("PDF")}); err == nil {
+		t.Error("unsupported type did not fail")
+	}
+
+	// Reading stops once the maximum size is exceeded
+	r := &endless{}
+	if _, err := NewDocumentFromReader(r, &ReadOptions{MaxSize: 10}); err != ErrDocumentTooLarge {
+		t.Errorf("endless reader returned %v, want ErrDocumentTooLarge", err)
+	}
+	if r.n > 11 {
+		t.Errorf("read %d bytes of endless reader", r.n)
+	}
+}
+
+func TestNewDocumentFromFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     *ReadOptions
+		wantType Type
+		wantErr  error
+	}{
+		{name: "plain text", path: write("notes.txt", "Hello"), wantType: TypePlainText},
+		{name: "sniffed HTML", path: write("page.txt", "<html>Hello</html>"), wantType: TypeHTML},
+		{name: "no extension", path: write("README", "Hello"), wantType: TypePlainText},
+		{name: ".html extension", path: write("page.html", "Hello"), wantType: TypeHTML},
+		{name: ".HTM extension", path: write("PAGE.HTM", "Hello"), wantType: TypeHTML},
+		{name: ".xhtml extension", path: write("page.xhtml", "Hello"), wantType: TypeHTML},
+		{
+			name:     "type overrides extension",
+			path:     write("raw.html", "<p>Hello</p>"),
+			opts:     &ReadOptions{Type: TypePlainText},
+			wantType: TypePlainText,
+		},
+		{
+			name:    "extension keeps max size",
+			path:    write("big.html", "Hello"),
+			opts:    &ReadOptions{MaxSize: 4},
+			wantErr: ErrDocumentTooLarge,
+		},
+		{name: "not text", path: write("image.html", "\x89PNG\r\n\x1a\n\xff"), wantErr: ErrNotText},
+	}
+
+	for _, tt := range tests {
+		doc, err := NewDocumentFromFile(tt.path, tt.opts)
+		if err != tt.wantErr {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && doc.Type() != tt.wantType {
+			t.Errorf("%s: got type %s, want %s", tt.name, doc.Type(), tt.wantType)
+		}
+	}
+
+	if _, err := NewDocumentFromFile(filepath.Join(dir, "missing.txt"), nil); !os.IsNotExist(err) {
+		t.Errorf("missing file returned %v", err)
+	}
+}
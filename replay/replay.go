@@ -0,0 +1,297 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package replay provides HTTP transports to record the interactions of a
+// gcnl.Client with the API into a cassette file, and to replay them later
+// without network access:
+//
+//	rec := replay.NewRecorder("testdata/analysis.json", nil)
+//	client.HTTPClient = &http.Client{Transport: rec}
+//
+//	rep, err := replay.NewReplayer("testdata/analysis.json")
+//	client.HTTPClient = &http.Client{Transport: rep}
+//
+// API keys and bearer tokens are redacted before being written.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// Redacted replaces credentials in recorded requests.
+const Redacted = "REDACTED"
+
+var ErrNoMatch = errors.New("replay: no recorded interaction matches the request")
+
+// sensitiveHeaders are redacted from recorded requests.
+var sensitiveHeaders = []string{"Authorization", "X-Goog-Api-Key"}
+
+// A Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// A Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// An Interaction is a request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// A Cassette is the list of interactions stored in a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load reads the cassette file at path.
+func Load(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Cassette)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("replay: %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to the file at path.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// redactURL removes the key query parameter from u.
+func redactURL(u *url.URL) string {
+	r := *u
+	q := r.Query()
+	if _, ok := q["key"]; ok {
+		q.Set("key", Redacted)
+		r.RawQuery = q.Encode()
+	}
+	return r.String()
+}
+
+// readBody reads and restores the body of r.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// A Recorder is an http.RoundTripper that sends requests using another
+// RoundTripper and records every interaction into a cassette file, which is
+// rewritten after each interaction. It is safe for concurrent use.
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder writing to the cassette file at path and
+// sending requests using transport. If transport is nil,
+// http.DefaultTransport is used.
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{path: path, transport: transport}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface for Recorder.
+func (rec *Recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header := r.Header.Clone()
+	for _, h := range sensitiveHeaders {
+		if len(header.Get(h)) > 0 {
+			header.Set(h, Redacted)
+		}
+	}
+
+	resp, err := rec.transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.cassette.Interactions = append(rec.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: r.Method,
+			URL:    redactURL(r.URL),
+			Header: header,
+			Body:   string(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	})
+	if err := rec.cassette.Save(rec.path); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// A Replayer is an http.RoundTripper answering requests with the responses of
+// a cassette file. A request matches an interaction with the same method, URL,
+// once redacted, and body. Interactions are replayed in recorded order, and an
+// interaction is reused once every match has been replayed. Requests without a
+// match fail with ErrNoMatch. A Replayer is safe for concurrent use.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer returns a Replayer of the cassette file at path.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCassetteReplayer(c), nil
+}
+
+// NewCassetteReplayer returns a Replayer of c.
+func NewCassetteReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface for Replayer.
+func (rep *Replayer) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	u := redactURL(r.URL)
+
+	rep.mu.Lock()
+	match := -1
+	for i, in := range rep.cassette.Interactions {
+		if in.Request.Method != r.Method || in.Request.URL != u || in.Request.Body != string(body) {
+			continue
+		}
+		if !rep.used[i] {
+			match = i
+			break
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match >= 0 {
+		rep.used[match] = true
+	}
+	rep.mu.Unlock()
+
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, r.Method, u)
+	}
+
+	recorded := rep.cassette.Interactions[match].Response
+	return &http.Response{
+		Status:        recorded.Status,
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       r,
+	}, nil
+}
+
+// Unused returns the number of recorded interactions not replayed yet, which
+// may reveal requests that a program no longer sends.
+func (rep *Replayer) Unused() int {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	n := 0
+	for _, used := range rep.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// fileExists reports whether a cassette file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// NewTransport returns a Replayer of the cassette file at path if it exists,
+// and a Recorder creating it otherwise. This records the interactions of a
+// test the first time it runs and replays them afterwards.
+func NewTransport(path string, transport http.RoundTripper) (http.RoundTripper, error) {
+	if fileExists(path) {
+		return NewReplayer(path)
+	}
+	return NewRecorder(path, transport), nil
+}
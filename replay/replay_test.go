@@ -0,0 +1,90 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package replay_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl/entities"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+	"github.com/jlubawy/go-gcnl/replay"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := gcnltest.NewServer()
+	srv.SetHeuristic(true)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := srv.Client()
+	c.Key = "supersecret"
+
+	rt, err := replay.NewTransport(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rt.(*replay.Recorder); !ok {
+		t.Fatalf("got %T without a cassette, want *replay.Recorder", rt)
+	}
+	c.HTTPClient = &http.Client{Transport: rt}
+
+	recorded, err := entities.NewClientRequest(c).FromPlainText("Larry Page founded Google.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "supersecret") {
+		t.Error("cassette contains the API key")
+	}
+
+	// Replay without the server
+	rt, err = replay.NewTransport(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, ok := rt.(*replay.Replayer)
+	if !ok {
+		t.Fatalf("got %T with a cassette, want *replay.Replayer", rt)
+	}
+	c.HTTPClient = &http.Client{Transport: rep}
+
+	replayed, err := entities.NewClientRequest(c).FromPlainText("Larry Page founded Google.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Len() != recorded.Len() || replayed.Len() != 2 {
+		t.Errorf("replayed %d entities, recorded %d", replayed.Len(), recorded.Len())
+	}
+	if n := rep.Unused(); n != 0 {
+		t.Errorf("%d interactions were not replayed", n)
+	}
+
+	_, err = entities.NewClientRequest(c).FromPlainText("Sergey Brin did too.")
+	if !errors.Is(err, replay.ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+}
@@ -0,0 +1,127 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// A RetryPolicy controls how a Client retries requests that failed because of
+// a network error or a transient API error (429, 500, 502, 503 and 504).
+// Other failures are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. The delay doubles with
+	// each following retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. If zero, the delay is not
+	// capped.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction, from 0.0 to 1.0, by which each delay is
+	// randomly reduced to avoid retrying in lockstep with other clients.
+	Jitter float64
+
+	// OnRetry, if not nil, is called before waiting for each retry.
+	OnRetry func(RetryInfo)
+}
+
+// RetryInfo describes a failed attempt that is about to be retried.
+type RetryInfo struct {
+	Method  string        // API method, for example "analyzeEntities"
+	Attempt int           // number of the failed attempt, starting at 1
+	Err     error         // error of the failed attempt
+	Delay   time.Duration // delay before the next attempt
+}
+
+// DefaultRetryPolicy returns a reasonable RetryPolicy for batch workloads.
+// Each call returns a new RetryPolicy, which may be modified freely.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// delay returns how long to wait before retrying after the given failed
+// attempt. ok is false if no attempts are left.
+func (p *RetryPolicy) delay(attempt int, err error) (d time.Duration, ok bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	d = p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+
+	// The server knows best how long to wait
+	if apiErr, isAPIErr := err.(*APIError); isAPIErr && apiErr.RetryAfter > d {
+		d = apiErr.RetryAfter
+	}
+
+	return d, true
+}
+
+// isNetworkError reports whether err, returned while sending a request or
+// reading its response, is a transport failure that may not happen again, such
+// as a refused or reset connection or a timeout. Failures that cannot succeed
+// when retried, such as invalid certificates, unsupported URLs or errors
+// returned by a RoundTripper or by CheckRedirect, are not network errors.
+func isNetworkError(err error) bool {
+	// url.Error implements net.Error itself, so look at what it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	var (
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
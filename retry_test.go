@@ -0,0 +1,223 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gcnl_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+)
+
+const method = "analyzeEntities"
+
+var body = map[string]interface{}{
+	"document": gcnl.NewPlainTextDocument("Larry Page founded Google."),
+}
+
+// fastRetries returns a policy retrying without delay, recording each retry.
+func fastRetries(retries *[]gcnl.RetryInfo) *gcnl.RetryPolicy {
+	p := gcnl.DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = time.Millisecond
+	p.OnRetry = func(info gcnl.RetryInfo) {
+		*retries = append(*retries, info)
+	}
+	return p
+}
+
+func TestRetryTemporaryAPIError(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.Fail(method, http.StatusServiceUnavailable, 2)
+
+	var retries []gcnl.RetryInfo
+	c := srv.Client()
+	c.Retry = fastRetries(&retries)
+
+	var v struct{}
+	if err := c.Do(method, body, &v); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+	if len(retries) != 2 || retries[0].Attempt != 1 || retries[1].Attempt != 2 {
+		t.Errorf("unexpected retries %+v", retries)
+	}
+	var apiErr *gcnl.APIError
+	if !errors.As(retries[0].Err, &apiErr) || !apiErr.IsTemporary() {
+		t.Errorf("retried error %v is not temporary", retries[0].Err)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.Fail(method, http.StatusTooManyRequests, 10)
+
+	var retries []gcnl.RetryInfo
+	c := srv.Client()
+	c.Retry = fastRetries(&retries)
+	c.Retry.MaxAttempts = 3
+
+	var v struct{}
+	err := c.Do(method, body, &v)
+	if !gcnl.IsQuotaExceeded(err) {
+		t.Fatalf("got error %v, want quota exceeded", err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+}
+
+func TestRetryPermanentAPIError(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.Fail(method, http.StatusBadRequest, 1)
+
+	var retries []gcnl.RetryInfo
+	c := srv.Client()
+	c.Retry = fastRetries(&retries)
+
+	var v struct{}
+	if err := c.Do(method, body, &v); !gcnl.IsInvalidArgument(err) {
+		t.Fatalf("got error %v, want invalid argument", err)
+	}
+	if len(retries) != 0 {
+		t.Errorf("permanent error was retried %d times", len(retries))
+	}
+}
+
+func TestRetryRetryAfter(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.Respond(method, http.StatusServiceUnavailable, `{"error":{"code":503,"status":"UNAVAILABLE"}}`)
+
+	var retries []gcnl.RetryInfo
+	c := srv.Client()
+	c.Retry = fastRetries(&retries)
+	c.HTTPClient = &http.Client{Transport: retryAfter{srv.Client().HTTPClient.Transport}}
+
+	var v struct{}
+	if err := c.Do(method, body, &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(retries) != 1 || retries[0].Delay != time.Second {
+		t.Errorf("unexpected retries %+v, want a delay of 1s", retries)
+	}
+}
+
+// retryAfter adds a Retry-After header to error responses.
+type retryAfter struct{ http.RoundTripper }
+
+func (rt retryAfter) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := rt.RoundTripper.RoundTrip(r)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		resp.Header.Set("Retry-After", "1")
+	}
+	return resp, err
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	// Find an address nobody listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	var retries []gcnl.RetryInfo
+	c := gcnl.NewClient(gcnltest.TestKey)
+	c.BaseURL = "http://" + addr
+	c.Retry = fastRetries(&retries)
+	c.Retry.MaxAttempts = 2
+
+	var v struct{}
+	if err := c.Do(method, body, &v); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(retries) != 1 {
+		t.Errorf("connection error was retried %d times, want 1", len(retries))
+	}
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct{ err error }
+
+func (rt failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return nil, rt.err
+}
+
+func TestRetryNotNetworkError(t *testing.T) {
+	errNoMatch := errors.New("no match")
+	for name, c := range map[string]*gcnl.Client{
+		"transport": {
+			Key:        gcnltest.TestKey,
+			HTTPClient: &http.Client{Transport: failingTransport{errNoMatch}},
+		},
+		"scheme": {
+			Key:     gcnltest.TestKey,
+			BaseURL: "gopher://localhost",
+		},
+	} {
+		var retries []gcnl.RetryInfo
+		c.Retry = fastRetries(&retries)
+
+		var v struct{}
+		if err := c.Do(method, body, &v); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if len(retries) != 0 {
+			t.Errorf("%s: error was retried %d times", name, len(retries))
+		}
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.Fail(method, http.StatusServiceUnavailable, 1)
+
+	c := srv.Client()
+	c.Retry = gcnl.DefaultRetryPolicy()
+	c.Retry.BaseDelay = time.Hour
+	c.Retry.MaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var v struct{}
+	if err := c.DoContext(ctx, method, body, &v); err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestDefaultRetryPolicyIsFresh(t *testing.T) {
+	p := gcnl.DefaultRetryPolicy()
+	p.MaxAttempts = 1
+	if gcnl.DefaultRetryPolicy().MaxAttempts == 1 {
+		t.Error("modifying a DefaultRetryPolicy changed the default")
+	}
+}
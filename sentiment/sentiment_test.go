@@ -0,0 +1,167 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package sentiment_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl"
+	"github.com/jlubawy/go-gcnl/gcnltest"
+	"github.com/jlubawy/go-gcnl/sentiment"
+)
+
+func TestZeroRequest(t *testing.T) {
+	var req sentiment.Request
+	if _, err := req.FromPlainText("What a great day."); err != sentiment.ErrNoClient {
+		t.Errorf("got error %v, want %v", err, sentiment.ErrNoClient)
+	}
+}
+
+func TestRequestConcurrentReuse(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	req := sentiment.NewClientRequest(srv.Client()).WithEncoding(gcnl.EncodingUTF16)
+	contents := []string{"What a great day.", "What a terrible day.", "It is a day."}
+
+	var wg sync.WaitGroup
+	for _, content := range contents {
+		wg.Add(1)
+		go func(content string) {
+			defer wg.Done()
+			res, err := req.FromPlainText(content)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(res.Sentences) != 1 || res.Sentences[0].Text.Content != content {
+				t.Errorf("got sentences %+v, want %q", res.Sentences, content)
+				return
+			}
+			if enc := res.Sentences[0].Text.Encoding; enc != gcnl.EncodingUTF16 {
+				t.Errorf("got encoding %s, want %s", enc, gcnl.EncodingUTF16)
+			}
+		}(content)
+	}
+	wg.Wait()
+}
+
+// span returns the JSON of a text span with the given content and offset.
+func span(content string, offset int) map[string]interface{} {
+	return map[string]interface{}{"content": content, "beginOffset": offset}
+}
+
+func TestResponseVersions(t *testing.T) {
+	tests := []struct {
+		version gcnl.Version
+		body    map[string]interface{}
+		want    sentiment.Result
+	}{
+		{
+			version: gcnl.VersionV1Beta1,
+			body: map[string]interface{}{
+				"documentSentiment": map[string]interface{}{"polarity": 1, "magnitude": 0.8},
+				"language":          "en",
+				"sentences": []interface{}{
+					map[string]interface{}{
+						"text":      span("Great food.", 0),
+						"sentiment": map[string]interface{}{"polarity": 1, "magnitude": 0.9},
+					},
+					map[string]interface{}{
+						"text":      span("Slow service.", 12),
+						"sentiment": map[string]interface{}{"polarity": -1, "magnitude": 0.3},
+					},
+				},
+			},
+			want: sentiment.Result{
+				DocumentSentiment: sentiment.Sentiment{Polarity: 1, Magnitude: 0.8},
+				Language:          "en",
+				Sentences: []sentiment.Sentence{
+					{Sentiment: sentiment.Sentiment{Polarity: 1, Magnitude: 0.9}},
+					{Sentiment: sentiment.Sentiment{Polarity: -1, Magnitude: 0.3}},
+				},
+			},
+		},
+		{
+			version: gcnl.VersionV1Beta2,
+			body: map[string]interface{}{
+				"documentSentiment": map[string]interface{}{"score": 0.2, "magnitude": 1.4},
+				"language":          "en",
+				"sentences": []interface{}{
+					map[string]interface{}{
+						"text":      span("Great food.", 0),
+						"sentiment": map[string]interface{}{"score": 0.9, "magnitude": 0.9},
+					},
+					map[string]interface{}{
+						"text":      span("Slow service.", 12),
+						"sentiment": map[string]interface{}{"score": -0.5, "magnitude": 0.5},
+					},
+				},
+			},
+			want: sentiment.Result{
+				DocumentSentiment: sentiment.Sentiment{Score: 0.2, Magnitude: 1.4},
+				Language:          "en",
+				Sentences: []sentiment.Sentence{
+					{Sentiment: sentiment.Sentiment{Score: 0.9, Magnitude: 0.9}},
+					{Sentiment: sentiment.Sentiment{Score: -0.5, Magnitude: 0.5}},
+				},
+			},
+		},
+	}
+
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+
+	for _, tt := range tests {
+		if err := srv.RespondJSON(sentiment.Method, tt.body); err != nil {
+			t.Fatal(err)
+		}
+		c := srv.Client()
+		c.Version = tt.version
+
+		res, err := sentiment.NewClientRequest(c).FromPlainText("Great food. Slow service.")
+		if err != nil {
+			t.Errorf("%s: %v", tt.version, err)
+			continue
+		}
+		if res.DocumentSentiment != tt.want.DocumentSentiment || res.Language != tt.want.Language {
+			t.Errorf("%s: got document sentiment %+v in %q, want %+v in %q", tt.version,
+				res.DocumentSentiment, res.Language, tt.want.DocumentSentiment, tt.want.Language)
+		}
+		if len(res.Sentences) != len(tt.want.Sentences) {
+			t.Errorf("%s: got %d sentences, want %d", tt.version, len(res.Sentences), len(tt.want.Sentences))
+			continue
+		}
+		for i, s := range res.Sentences {
+			if want := tt.want.Sentences[i].Sentiment; s.Sentiment != want {
+				t.Errorf("%s: sentence %d: got %+v, want %+v", tt.version, i, s.Sentiment, want)
+			}
+		}
+		if s := res.Sentences[1].Text; s.Content != "Slow service." || s.BeginOffset != 12 {
+			t.Errorf("%s: got second sentence %+v", tt.version, s)
+		}
+	}
+
+	for i, r := range srv.Requests() {
+		if want := string(tests[i].version); r.Version != want {
+			t.Errorf("request %d sent to %s, want %s", i, r.Version, want)
+		}
+	}
+}
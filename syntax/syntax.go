@@ -0,0 +1,175 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package syntax
+
+import (
+	"context"
+
+	"github.com/jlubawy/go-gcnl"
+)
+
+// Method is the name of the API method used by requests.
+const Method = "analyzeSyntax"
+
+// Endpoint is the URL of the method when using the default Client settings.
+// analyzeSyntax is only available starting with v1beta2.
+const Endpoint = "https://language.googleapis.com/v1beta2/documents:analyzeSyntax"
+
+var (
+	ErrMissingKey = gcnl.ErrMissingKey
+	ErrNoClient   = gcnl.ErrNoClient
+)
+
+// A Token is the smallest syntactic building block of the text.
+type Token struct {
+	Text           gcnl.TextSpan  `json:"text"`
+	PartOfSpeech   PartOfSpeech   `json:"partOfSpeech"`
+	DependencyEdge DependencyEdge `json:"dependencyEdge"`
+	Lemma          string         `json:"lemma"`
+}
+
+// A DependencyEdge links a Token to its head Token in the dependency tree.
+// HeadTokenIndex is an index into the Tokens of the same Result. The root
+// token of a sentence is its own head.
+type DependencyEdge struct {
+	HeadTokenIndex int   `json:"headTokenIndex"`
+	Label          Label `json:"label"`
+}
+
+// A Sentence is a sentence found in the document.
+type Sentence struct {
+	Text gcnl.TextSpan `json:"text"`
+}
+
+// A Result contains the sentences and tokens of a document.
+type Result struct {
+	Sentences []Sentence `json:"sentences"`
+	Tokens    Tokens     `json:"tokens"`
+	Language  string     `json:"language"`
+}
+
+// A Request sends documents to the syntax API. It must be created using
+// NewRequest or NewClientRequest; the zero value fails with ErrNoClient. A
+// Request is safe for concurrent use and may be reused for any number of
+// documents.
+type Request struct {
+	client *gcnl.Client
+	enc    gcnl.Encoding
+}
+
+// requestBody is the JSON object sent to the syntax API.
+type requestBody struct {
+	Doc gcnl.Document `json:"document"`
+	Enc gcnl.Encoding `json:"encodingType"`
+}
+
+// Document satisfies the gcnl.DocumentRequest interface for requestBody.
+func (body *requestBody) Document() gcnl.Document {
+	return body.Doc
+}
+
+// NewRequest returns a Request object with the given API key.
+func NewRequest(key string) *Request {
+	return NewClientRequest(gcnl.NewClient(key))
+}
+
+// NewClientRequest returns a Request object sent using the given Client.
+func NewClientRequest(c *gcnl.Client) *Request {
+	return &Request{
+		client: c,
+		enc:    gcnl.EncodingDefault,
+	}
+}
+
+// WithEncoding returns a copy of the request that has the API compute offsets
+// using enc. Use gcnl.ByteOffset to convert the returned offsets into indexes
+// of the document content.
+func (req *Request) WithEncoding(enc gcnl.Encoding) *Request {
+	return &Request{
+		client: req.client,
+		enc:    enc,
+	}
+}
+
+// Encoding returns the encoding used by the API to compute offsets.
+func (req *Request) Encoding() gcnl.Encoding {
+	return req.enc
+}
+
+// FromURL analyzes the syntax of the content retrieved from a given URL. It
+// expects the content retrieved from URL to be valid HTML.
+func (req *Request) FromURL(url string) (res *Result, err error) {
+	return req.FromURLContext(context.Background(), url)
+}
+
+// FromURLContext is like FromURL but uses ctx for both fetching the URL, using
+// the Fetcher of the client, and the API request.
+func (req *Request) FromURLContext(ctx context.Context, url string) (res *Result, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+	doc, err := req.client.FetchHTML(ctx, url)
+	if err != nil {
+		return
+	}
+	return req.FromDocumentContext(ctx, doc)
+}
+
+// FromPlainText analyzes the syntax of the given plain text.
+func (req *Request) FromPlainText(content string) (res *Result, err error) {
+	return req.FromPlainTextContext(context.Background(), content)
+}
+
+// FromPlainTextContext is like FromPlainText but uses ctx for the API request.
+func (req *Request) FromPlainTextContext(ctx context.Context, content string) (res *Result, err error) {
+	return req.FromDocumentContext(ctx, gcnl.NewPlainTextDocument(content))
+}
+
+// FromDocument analyzes the syntax of the given document.
+func (req *Request) FromDocument(doc gcnl.Document) (res *Result, err error) {
+	return req.FromDocumentContext(context.Background(), doc)
+}
+
+// FromDocumentContext is like FromDocument but uses ctx for the API request.
+func (req *Request) FromDocumentContext(ctx context.Context, doc gcnl.Document) (res *Result, err error) {
+	return req.do(ctx, doc)
+}
+
+// Do makes the actual API request for a given document.
+func (req *Request) do(ctx context.Context, doc gcnl.Document) (res *Result, err error) {
+	if req.client == nil {
+		err = ErrNoClient
+		return
+	}
+
+	res = new(Result)
+	body := &requestBody{Doc: doc, Enc: req.enc}
+	if err = req.client.DoContext(ctx, Method, body, res); err != nil {
+		res = nil
+		return
+	}
+
+	for i := range res.Sentences {
+		res.Sentences[i].Text.Encoding = req.enc
+	}
+	for i := range res.Tokens {
+		res.Tokens[i].Text.Encoding = req.enc
+	}
+	return
+}
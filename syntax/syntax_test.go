@@ -0,0 +1,200 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package syntax_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jlubawy/go-gcnl/gcnltest"
+	"github.com/jlubawy/go-gcnl/syntax"
+)
+
+func TestZeroRequest(t *testing.T) {
+	var req syntax.Request
+	if _, err := req.FromPlainText("Larry Page founded Google."); err != syntax.ErrNoClient {
+		t.Errorf("got error %v, want %v", err, syntax.ErrNoClient)
+	}
+}
+
+func TestRequestConcurrentReuse(t *testing.T) {
+	srv := gcnltest.NewServer()
+	defer srv.Close()
+	srv.SetHeuristic(true)
+
+	req := syntax.NewClientRequest(srv.Client())
+	contents := []string{"Larry founded Google.", "Sergey likes cats.", "Eric sold it."}
+
+	var wg sync.WaitGroup
+	for _, content := range contents {
+		wg.Add(1)
+		go func(content string) {
+			defer wg.Done()
+			res, err := req.FromPlainText(content)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(res.Sentences) != 1 || res.Sentences[0].Text.Content != content {
+				t.Errorf("got sentences %+v, want %q", res.Sentences, content)
+			}
+		}(content)
+	}
+	wg.Wait()
+
+	for _, r := range srv.Requests() {
+		if r.Version != "v1beta2" {
+			t.Errorf("request sent to %s, want v1beta2", r.Version)
+		}
+	}
+}
+
+// edge returns a token attached to head with the given label.
+func edge(head int, label syntax.Label) syntax.Token {
+	return syntax.Token{DependencyEdge: syntax.DependencyEdge{HeadTokenIndex: head, Label: label}}
+}
+
+// twoSentences holds the dependency trees of "Larry Page founded Google." and
+// "He likes big cats."
+var twoSentences = syntax.Tokens{
+	edge(1, syntax.LabelNn),    // 0 Larry
+	edge(2, syntax.LabelNsubj), // 1 Page
+	edge(2, syntax.LabelRoot),  // 2 founded
+	edge(2, syntax.LabelDobj),  // 3 Google
+	edge(2, syntax.LabelP),     // 4 .
+	edge(6, syntax.LabelNsubj), // 5 He
+	edge(6, syntax.LabelRoot),  // 6 likes
+	edge(8, syntax.LabelAmod),  // 7 big
+	edge(6, syntax.LabelDobj),  // 8 cats
+	edge(6, syntax.LabelP),     // 9 .
+}
+
+// malformed contains a cycle between tokens 0 and 1 and a head out of range.
+var malformed = syntax.Tokens{
+	edge(1, syntax.LabelDep),
+	edge(0, syntax.LabelDep),
+	edge(2, syntax.LabelRoot),
+	edge(99, syntax.LabelDep),
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTree(t *testing.T) {
+	ts := twoSentences
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{"Roots", ts.Roots(), []int{2, 6}},
+		{"Children of first root", ts.Children(2), []int{1, 3, 4}},
+		{"Children of second root", ts.Children(6), []int{5, 8, 9}},
+		{"Children of leaf", ts.Children(0), nil},
+		{"ChildrenWithLabel DOBJ", ts.ChildrenWithLabel(2, syntax.LabelDobj), []int{3}},
+		{"ChildrenWithLabel NSUBJ", ts.ChildrenWithLabel(6, syntax.LabelNsubj), []int{5}},
+		{"ChildrenWithLabel missing", ts.ChildrenWithLabel(2, syntax.LabelAmod), nil},
+		{"Subtree of first root", ts.Subtree(2), []int{0, 1, 2, 3, 4}},
+		{"Subtree of second root", ts.Subtree(6), []int{5, 6, 7, 8, 9}},
+		{"Subtree of inner token", ts.Subtree(8), []int{7, 8}},
+		{"Subtree of leaf", ts.Subtree(4), []int{4}},
+		{"Roots of malformed", malformed.Roots(), []int{2, 3}},
+		{"Subtree of cycle", malformed.Subtree(0), []int{0, 1}},
+	}
+
+	for _, tt := range tests {
+		if !equalInts(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	type visit struct{ i, depth int }
+
+	tests := []struct {
+		name string
+		ts   syntax.Tokens
+		root int
+		skip int // token whose children are skipped, or -1
+		want []visit
+	}{
+		{
+			name: "first sentence",
+			ts:   twoSentences,
+			root: 2,
+			skip: -1,
+			want: []visit{{2, 0}, {1, 1}, {0, 2}, {3, 1}, {4, 1}},
+		},
+		{
+			name: "second sentence",
+			ts:   twoSentences,
+			root: 6,
+			skip: -1,
+			want: []visit{{6, 0}, {5, 1}, {8, 1}, {7, 2}, {9, 1}},
+		},
+		{
+			name: "skip children",
+			ts:   twoSentences,
+			root: 6,
+			skip: 8,
+			want: []visit{{6, 0}, {5, 1}, {8, 1}, {9, 1}},
+		},
+		{
+			name: "skip root",
+			ts:   twoSentences,
+			root: 2,
+			skip: 2,
+			want: []visit{{2, 0}},
+		},
+		{
+			name: "cycle",
+			ts:   malformed,
+			root: 0,
+			skip: -1,
+			want: []visit{{0, 0}, {1, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []visit
+		tt.ts.Walk(tt.root, func(i, depth int) bool {
+			got = append(got, visit{i, depth})
+			return i != tt.skip
+		})
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package syntax
+
+// A PartOfSpeech contains the morphological information of a Token. Fields
+// other than Tag are only populated by the v1beta2 and later APIs and are set to
+// their *_UNKNOWN value when not applicable.
+type PartOfSpeech struct {
+	Tag         Tag         `json:"tag"`
+	Aspect      Aspect      `json:"aspect,omitempty"`
+	Case        Case        `json:"case,omitempty"`
+	Form        Form        `json:"form,omitempty"`
+	Gender      Gender      `json:"gender,omitempty"`
+	Mood        Mood        `json:"mood,omitempty"`
+	Number      Number      `json:"number,omitempty"`
+	Person      Person      `json:"person,omitempty"`
+	Proper      Proper      `json:"proper,omitempty"`
+	Reciprocity Reciprocity `json:"reciprocity,omitempty"`
+	Tense       Tense       `json:"tense,omitempty"`
+	Voice       Voice       `json:"voice,omitempty"`
+}
+
+// A Tag is the coarse-grained part of speech of a Token.
+type Tag string
+
+const (
+	TagUnknown Tag = "UNKNOWN"
+	TagAdj         = "ADJ"
+	TagAdp         = "ADP"
+	TagAdv         = "ADV"
+	TagConj        = "CONJ"
+	TagDet         = "DET"
+	TagNoun        = "NOUN"
+	TagNum         = "NUM"
+	TagPron        = "PRON"
+	TagPrt         = "PRT"
+	TagPunct       = "PUNCT"
+	TagVerb        = "VERB"
+	TagX           = "X"
+	TagAffix       = "AFFIX"
+)
+
+// An Aspect characterizes how a verb action is viewed in time.
+type Aspect string
+
+const (
+	AspectUnknown      Aspect = "ASPECT_UNKNOWN"
+	AspectPerfective          = "PERFECTIVE"
+	AspectImperfective        = "IMPERFECTIVE"
+	AspectProgressive         = "PROGRESSIVE"
+)
+
+// A Case is the grammatical function of a noun or pronoun.
+type Case string
+
+const (
+	CaseUnknown       Case = "CASE_UNKNOWN"
+	CaseAccusative         = "ACCUSATIVE"
+	CaseAdverbial          = "ADVERBIAL"
+	CaseComplementive      = "COMPLEMENTIVE"
+	CaseDative             = "DATIVE"
+	CaseGenitive           = "GENITIVE"
+	CaseInstrumental       = "INSTRUMENTAL"
+	CaseLocative           = "LOCATIVE"
+	CaseNominative         = "NOMINATIVE"
+	CaseOblique            = "OBLIQUE"
+	CasePartitive          = "PARTITIVE"
+	CasePrepositional      = "PREPOSITIONAL"
+	CaseReflexive          = "REFLEXIVE_CASE"
+	CaseRelative           = "RELATIVE_CASE"
+	CaseVocative           = "VOCATIVE"
+)
+
+// A Form is a morphological form, mostly used in Korean.
+type Form string
+
+const (
+	FormUnknown        Form = "FORM_UNKNOWN"
+	FormAdnomial            = "ADNOMIAL"
+	FormAuxiliary           = "AUXILIARY"
+	FormComplementizer      = "COMPLEMENTIZER"
+	FormFinalEnding         = "FINAL_ENDING"
+	FormGerund              = "GERUND"
+	FormRealis              = "REALIS"
+	FormIrrealis            = "IRREALIS"
+	FormShort               = "SHORT"
+	FormLong                = "LONG"
+	FormOrder               = "ORDER"
+	FormSpecific            = "SPECIFIC"
+)
+
+// A Gender is the grammatical gender of a noun, adjective or pronoun.
+type Gender string
+
+const (
+	GenderUnknown   Gender = "GENDER_UNKNOWN"
+	GenderFeminine         = "FEMININE"
+	GenderMasculine        = "MASCULINE"
+	GenderNeuter           = "NEUTER"
+)
+
+// A Mood is the grammatical mood of a verb.
+type Mood string
+
+const (
+	MoodUnknown       Mood = "MOOD_UNKNOWN"
+	MoodConditional        = "CONDITIONAL_MOOD"
+	MoodImperative         = "IMPERATIVE"
+	MoodIndicative         = "INDICATIVE"
+	MoodInterrogative      = "INTERROGATIVE"
+	MoodJussive            = "JUSSIVE"
+	MoodSubjunctive        = "SUBJUNCTIVE"
+)
+
+// A Number is the grammatical number of a word.
+type Number string
+
+const (
+	NumberUnknown  Number = "NUMBER_UNKNOWN"
+	NumberSingular        = "SINGULAR"
+	NumberPlural          = "PLURAL"
+	NumberDual            = "DUAL"
+)
+
+// A Person distinguishes the speaker, the addressee and others.
+type Person string
+
+const (
+	PersonUnknown   Person = "PERSON_UNKNOWN"
+	PersonFirst            = "FIRST"
+	PersonSecond           = "SECOND"
+	PersonThird            = "THIRD"
+	PersonReflexive        = "REFLEXIVE_PERSON"
+)
+
+// A Proper specifies whether a noun is part of a proper name.
+type Proper string
+
+const (
+	ProperUnknown   Proper = "PROPER_UNKNOWN"
+	ProperProper           = "PROPER"
+	ProperNotProper        = "NOT_PROPER"
+)
+
+// A Reciprocity specifies whether a pronoun is reciprocal.
+type Reciprocity string
+
+const (
+	ReciprocityUnknown       Reciprocity = "RECIPROCITY_UNKNOWN"
+	ReciprocityReciprocal                = "RECIPROCAL"
+	ReciprocityNonReciprocal             = "NON_RECIPROCAL"
+)
+
+// A Tense is the time reference of a verb.
+type Tense string
+
+const (
+	TenseUnknown     Tense = "TENSE_UNKNOWN"
+	TenseConditional       = "CONDITIONAL_TENSE"
+	TenseFuture            = "FUTURE"
+	TensePast              = "PAST"
+	TensePresent           = "PRESENT"
+	TenseImperfect         = "IMPERFECT"
+	TensePluperfect        = "PLUPERFECT"
+)
+
+// A Voice is the relationship between the action of a verb and its participants.
+type Voice string
+
+const (
+	VoiceUnknown   Voice = "VOICE_UNKNOWN"
+	VoiceActive          = "ACTIVE"
+	VoiceCausative       = "CAUSATIVE"
+	VoicePassive         = "PASSIVE"
+)
+
+// A Label is the type of a DependencyEdge.
+type Label string
+
+const (
+	LabelUnknown      Label = "UNKNOWN"
+	LabelAbbrev             = "ABBREV"
+	LabelAcomp              = "ACOMP"
+	LabelAdvcl              = "ADVCL"
+	LabelAdvmod             = "ADVMOD"
+	LabelAmod               = "AMOD"
+	LabelAppos              = "APPOS"
+	LabelAttr               = "ATTR"
+	LabelAux                = "AUX"
+	LabelAuxpass            = "AUXPASS"
+	LabelCc                 = "CC"
+	LabelCcomp              = "CCOMP"
+	LabelConj               = "CONJ"
+	LabelCsubj              = "CSUBJ"
+	LabelCsubjpass          = "CSUBJPASS"
+	LabelDep                = "DEP"
+	LabelDet                = "DET"
+	LabelDiscourse          = "DISCOURSE"
+	LabelDobj               = "DOBJ"
+	LabelExpl               = "EXPL"
+	LabelGoeswith           = "GOESWITH"
+	LabelIobj               = "IOBJ"
+	LabelMark               = "MARK"
+	LabelMwe                = "MWE"
+	LabelMwv                = "MWV"
+	LabelNeg                = "NEG"
+	LabelNn                 = "NN"
+	LabelNpadvmod           = "NPADVMOD"
+	LabelNsubj              = "NSUBJ"
+	LabelNsubjpass          = "NSUBJPASS"
+	LabelNum                = "NUM"
+	LabelNumber             = "NUMBER"
+	LabelP                  = "P"
+	LabelParataxis          = "PARATAXIS"
+	LabelPartmod            = "PARTMOD"
+	LabelPcomp              = "PCOMP"
+	LabelPobj               = "POBJ"
+	LabelPoss               = "POSS"
+	LabelPostneg            = "POSTNEG"
+	LabelPrecomp            = "PRECOMP"
+	LabelPreconj            = "PRECONJ"
+	LabelPredet             = "PREDET"
+	LabelPref               = "PREF"
+	LabelPrep               = "PREP"
+	LabelPronl              = "PRONL"
+	LabelPrt                = "PRT"
+	LabelPs                 = "PS"
+	LabelQuantmod           = "QUANTMOD"
+	LabelRcmod              = "RCMOD"
+	LabelRcmodrel           = "RCMODREL"
+	LabelRdrop              = "RDROP"
+	LabelRef                = "REF"
+	LabelRemnant            = "REMNANT"
+	LabelReparandum         = "REPARANDUM"
+	LabelRoot               = "ROOT"
+	LabelSnum               = "SNUM"
+	LabelSuff               = "SUFF"
+	LabelTmod               = "TMOD"
+	LabelTopic              = "TOPIC"
+	LabelVmod               = "VMOD"
+	LabelVocative           = "VOCATIVE"
+	LabelXcomp              = "XCOMP"
+	LabelSuffix             = "SUFFIX"
+	LabelTitle              = "TITLE"
+	LabelAdvphmod           = "ADVPHMOD"
+	LabelAuxcaus            = "AUXCAUS"
+	LabelAuxvv              = "AUXVV"
+	LabelDtmod              = "DTMOD"
+	LabelForeign            = "FOREIGN"
+	LabelKw                 = "KW"
+	LabelList               = "LIST"
+	LabelNomc               = "NOMC"
+	LabelNomcsubj           = "NOMCSUBJ"
+	LabelNomcsubjpass       = "NOMCSUBJPASS"
+	LabelNumc               = "NUMC"
+	LabelCop                = "COP"
+	LabelDislocated         = "DISLOCATED"
+	LabelAsp                = "ASP"
+	LabelGmod               = "GMOD"
+	LabelGobj               = "GOBJ"
+	LabelInfmod             = "INFMOD"
+	LabelMes                = "MES"
+	LabelNcomp              = "NCOMP"
+)
@@ -0,0 +1,126 @@
+// go-gcnl - Golang library for accessing the Google Cloud Natural Language API
+// Copyright (C) 2016 Josh Lubawy <jlubawy@gmail.com>
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package syntax
+
+// Tokens is a slice of tokens forming one or more dependency trees, one per
+// sentence. Tokens are referred to by their index in the slice.
+type Tokens []Token
+
+// Head returns the index of the head of token i. ok is false if i is a root
+// token or if its head is out of range.
+func (ts Tokens) Head(i int) (head int, ok bool) {
+	head = ts[i].DependencyEdge.HeadTokenIndex
+	if head == i || head < 0 || head >= len(ts) {
+		return -1, false
+	}
+	return head, true
+}
+
+// IsRoot reports whether token i is the root of a dependency tree.
+func (ts Tokens) IsRoot(i int) bool {
+	_, ok := ts.Head(i)
+	return !ok
+}
+
+// Roots returns the indexes of the root tokens, in document order.
+func (ts Tokens) Roots() []int {
+	var roots []int
+	for i := range ts {
+		if ts.IsRoot(i) {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// Children returns the indexes of the tokens whose head is token i, in
+// document order.
+func (ts Tokens) Children(i int) []int {
+	var children []int
+	for j := range ts {
+		if head, ok := ts.Head(j); ok && head == i {
+			children = append(children, j)
+		}
+	}
+	return children
+}
+
+// ChildrenWithLabel returns the children of token i attached with the given
+// label.
+func (ts Tokens) ChildrenWithLabel(i int, label Label) []int {
+	var children []int
+	for _, j := range ts.Children(i) {
+		if ts[j].DependencyEdge.Label == label {
+			children = append(children, j)
+		}
+	}
+	return children
+}
+
+// A WalkFunc is called for each token visited by Walk with the token index and
+// its depth relative to the starting token. Returning false skips the
+// children of the token.
+type WalkFunc func(i, depth int) bool
+
+// Walk traverses the dependency tree rooted at token root in depth-first
+// order, calling fn for each token including root. Children are visited in
+// document order.
+func (ts Tokens) Walk(root int, fn WalkFunc) {
+	children := make(map[int][]int)
+	for j := range ts {
+		if head, ok := ts.Head(j); ok {
+			children[head] = append(children[head], j)
+		}
+	}
+
+	visited := make(map[int]bool)
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		// Guard against malformed responses containing cycles
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+
+		if !fn(i, depth) {
+			return
+		}
+		for _, j := range children[i] {
+			walk(j, depth+1)
+		}
+	}
+	walk(root, 0)
+}
+
+// Subtree returns the indexes of token i and all of its descendants, in
+// document order.
+func (ts Tokens) Subtree(i int) []int {
+	in := make(map[int]bool)
+	ts.Walk(i, func(j, _ int) bool {
+		in[j] = true
+		return true
+	})
+
+	subtree := make([]int, 0, len(in))
+	for j := range ts {
+		if in[j] {
+			subtree = append(subtree, j)
+		}
+	}
+	return subtree
+}